@@ -0,0 +1,276 @@
+// Package analyzer provides a go/analysis checker for structsconv rules definitions.
+//
+// It statically inspects the structsconv.RulesDefinition literals and the structsconv.RulesSet used by them,
+// reporting the errors that structsconv.RegisterRulesDefinitions would only detect at startup:
+//   - rule keys that are not fields of the target struct
+//   - source field names that are not fields of the source struct
+//   - rule functions whose return type does not match the target field type, or the argument of the field
+//     setter when the definition sets Setters
+//
+// The RulesSet can be a literal in the definition, or a variable of the same function initialized
+// with a literal and filled with index assignments, e.g. rules["Zip"] = "ZipCode".
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const structsconvPath = "github.com/rendis/structsconv"
+
+// Analyzer checks the structsconv rules definitions.
+var Analyzer = &analysis.Analyzer{
+	Name:     "structsconv",
+	Doc:      "check structsconv.RulesSet keys, source field names and rule function return types",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// ruleEntry is a rule of a RulesSet, key is the target field name.
+type ruleEntry struct {
+	keyExpr ast.Expr
+	key     string
+	value   ast.Expr
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// rules of the RulesSet variables, from their literal initializers and index assignments
+	varRules := make(map[types.Object][]ruleEntry)
+	addLiteral := func(ident *ast.Ident, value ast.Expr) {
+		lit, ok := astutil.Unparen(value).(*ast.CompositeLit)
+		if !ok || !isStructsconvType(pass.TypesInfo.TypeOf(lit), "RulesSet") {
+			return
+		}
+		if obj := objectOf(pass, ident); obj != nil {
+			varRules[obj] = append(varRules[obj], literalRules(pass, lit)...)
+		}
+	}
+
+	nodeFilter := []ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					addLiteral(name, n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return
+			}
+			for i, lhs := range n.Lhs {
+				switch lhs := lhs.(type) {
+				case *ast.Ident:
+					addLiteral(lhs, n.Rhs[i])
+				case *ast.IndexExpr:
+					ident, ok := astutil.Unparen(lhs.X).(*ast.Ident)
+					if !ok || !isStructsconvType(pass.TypesInfo.TypeOf(ident), "RulesSet") {
+						continue
+					}
+					key, ok := stringConst(pass, lhs.Index)
+					if obj := objectOf(pass, ident); ok && obj != nil {
+						varRules[obj] = append(varRules[obj], ruleEntry{keyExpr: lhs.Index, key: key, value: n.Rhs[i]})
+					}
+				}
+			}
+		}
+	})
+
+	insp.Preorder([]ast.Node{(*ast.CompositeLit)(nil)}, func(n ast.Node) {
+		lit := n.(*ast.CompositeLit)
+		if !isStructsconvType(pass.TypesInfo.TypeOf(lit), "RulesDefinition") {
+			return
+		}
+		var source, target *types.Struct
+		var targetType types.Type
+		var setters bool
+		var rules []ruleEntry
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			name, _ := kv.Key.(*ast.Ident)
+			if name == nil {
+				continue
+			}
+			switch name.Name {
+			case "Source":
+				source = structOf(pass.TypesInfo.TypeOf(kv.Value))
+			case "Target":
+				targetType = pass.TypesInfo.TypeOf(kv.Value)
+				target = structOf(targetType)
+			case "Setters":
+				tv := pass.TypesInfo.Types[kv.Value]
+				setters = tv.Value == nil || constant.BoolVal(tv.Value)
+			case "Rules":
+				switch v := astutil.Unparen(kv.Value).(type) {
+				case *ast.CompositeLit:
+					rules = literalRules(pass, v)
+				case *ast.Ident:
+					rules = varRules[objectOf(pass, v)]
+				}
+			}
+		}
+		if source == nil || target == nil {
+			return
+		}
+		for _, r := range rules {
+			var setter types.Type
+			if setters {
+				setter = setterParam(targetType, r.key)
+			}
+			checkRule(pass, r, source, target, setter)
+		}
+	})
+	return nil, nil
+}
+
+// checkRule reports the errors of a rule, setter is the argument type of the target field setter, if it is used.
+func checkRule(pass *analysis.Pass, r ruleEntry, source, target *types.Struct, setter types.Type) {
+	tf := lookupField(target, r.key)
+	if tf == nil {
+		pass.Reportf(r.keyExpr.Pos(), "field '%s' is not present in target struct", r.key)
+		return
+	}
+	fieldType := tf.Type()
+	if setter != nil {
+		fieldType = setter
+	}
+
+	value := astutil.Unparen(r.value)
+	if ident, ok := value.(*ast.Ident); ok && ident.Name == "nil" {
+		return // ignored field
+	}
+	if name, ok := stringConst(pass, value); ok {
+		if lookupField(source, name) == nil {
+			pass.Reportf(value.Pos(), "field '%s' is not present in source struct", name)
+		}
+		return
+	}
+
+	sig, ok := pass.TypesInfo.TypeOf(value).Underlying().(*types.Signature)
+	if !ok {
+		return
+	}
+	results := sig.Results()
+	if results.Len() == 0 || results.Len() > 2 ||
+		(results.Len() == 2 && !types.Identical(results.At(1).Type(), types.Universe.Lookup("error").Type())) {
+		pass.Reportf(value.Pos(), "function for field '%s' must return '%s', optionally followed by an error", r.key, fieldType)
+		return
+	}
+	if !types.Identical(results.At(0).Type(), fieldType) {
+		pass.Reportf(value.Pos(), "function for field '%s' must return type '%s', currently returns '%s'", r.key, fieldType, results.At(0).Type())
+	}
+}
+
+// setterParam returns the argument type of the setter of the field, e.g. SetName for the fields Name and name,
+// declared by the target type t or a pointer to it. Returns nil if there is no setter with one argument.
+func setterParam(t types.Type, field string) types.Type {
+	if t == nil || field == "" {
+		return nil
+	}
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, "Set"+strings.ToUpper(field[:1])+field[1:])
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return nil
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 1 {
+		return nil
+	}
+	return sig.Params().At(0).Type()
+}
+
+// literalRules returns the rules of a RulesSet literal.
+func literalRules(pass *analysis.Pass, lit *ast.CompositeLit) []ruleEntry {
+	var rules []ruleEntry
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := stringConst(pass, kv.Key); ok {
+			rules = append(rules, ruleEntry{keyExpr: kv.Key, key: key, value: kv.Value})
+		}
+	}
+	return rules
+}
+
+// lookupField returns the field of the struct with the given name, promoted fields of embedded structs included.
+func lookupField(s *types.Struct, name string) *types.Var {
+	for i := 0; i < s.NumFields(); i++ {
+		if s.Field(i).Name() == name {
+			return s.Field(i)
+		}
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		if !s.Field(i).Embedded() {
+			continue
+		}
+		if es := structOf(s.Field(i).Type()); es != nil {
+			if f := lookupField(es, name); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// structOf returns the struct of t, or of the type pointed by t.
+func structOf(t types.Type) *types.Struct {
+	if t == nil {
+		return nil
+	}
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, _ := t.Underlying().(*types.Struct)
+	return s
+}
+
+// isStructsconvType returns true if t is the structsconv type with the given name, or a pointer to it.
+func isStructsconvType(t types.Type, name string) bool {
+	if t == nil {
+		return false
+	}
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == name && obj.Pkg() != nil && obj.Pkg().Path() == structsconvPath
+}
+
+// stringConst returns the value of a constant string expression.
+func stringConst(pass *analysis.Pass, e ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// objectOf returns the object an identifier defines or uses.
+func objectOf(pass *analysis.Pass, ident *ast.Ident) types.Object {
+	if obj := pass.TypesInfo.Defs[ident]; obj != nil {
+		return obj
+	}
+	return pass.TypesInfo.Uses[ident]
+}
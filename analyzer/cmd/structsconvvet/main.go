@@ -0,0 +1,14 @@
+// Command structsconvvet checks structsconv rules definitions, use it with go vet:
+//
+//	go install github.com/rendis/structsconv/analyzer/cmd/structsconvvet@latest
+//	go vet -vettool=$(which structsconvvet) ./...
+package main
+
+import (
+	"github.com/rendis/structsconv/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
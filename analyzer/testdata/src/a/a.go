@@ -0,0 +1,73 @@
+package a
+
+import (
+	"github.com/rendis/structsconv"
+)
+
+type Audit struct{ CreatedBy string }
+
+type AddressDto struct {
+	Street  string
+	ZipCode string
+}
+
+type Address struct {
+	Audit
+	Street  string
+	Zip     string
+	Number  int
+	Country string
+}
+
+func literalRules() structsconv.RulesDefinition {
+	return structsconv.RulesDefinition{
+		Source: AddressDto{},
+		Target: Address{},
+		Rules: structsconv.RulesSet{
+			"Zip":       "ZipCode",
+			"CreatedBy": func() string { return "system" },
+			"Unknown":   nil,                                   // want `field 'Unknown' is not present in target struct`
+			"Street":    "Name",                                // want `field 'Name' is not present in source struct`
+			"Number":    func() string { return "1" },          // want `function for field 'Number' must return type 'int', currently returns 'string'`
+			"Country":   func() (string, int) { return "", 0 }, // want `function for field 'Country' must return 'string', optionally followed by an error`
+		},
+	}
+}
+
+func assignedRules() *structsconv.RulesDefinition {
+	var rules = structsconv.RulesSet{}
+
+	rules["Zip"] = "Zip" // want `field 'Zip' is not present in source struct`
+	rules["Number"] = func(a AddressDto) (int, error) { return 0, nil }
+	rules["Country"] = countryName // want `function for field 'Country' must return type 'string', currently returns 'int'`
+	rules["Other"] = "Street"      // want `field 'Other' is not present in target struct`
+
+	return &structsconv.RulesDefinition{
+		Rules:  rules,
+		Source: AddressDto{},
+		Target: &Address{},
+	}
+}
+
+func countryName() int { return 0 }
+
+type Email string
+
+type Customer struct {
+	name  string
+	email Email
+}
+
+func (c *Customer) SetEmail(e string) { c.email = Email(e) }
+
+func setterRules() structsconv.RulesDefinition {
+	return structsconv.RulesDefinition{
+		Source:  AddressDto{},
+		Target:  Customer{},
+		Setters: true,
+		Rules: structsconv.RulesSet{
+			"email": func() string { return "a@b.c" },
+			"name":  func() int { return 0 }, // want `function for field 'name' must return type 'string', currently returns 'int'`
+		},
+	}
+}
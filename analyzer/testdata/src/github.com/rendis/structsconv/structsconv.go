@@ -0,0 +1,14 @@
+package structsconv
+
+type RulesDefinition struct {
+	Source      interface{}
+	Target      interface{}
+	Rules       RulesSet
+	Constructor interface{}
+	Setters     bool
+	BeforeMap   interface{}
+	AfterMap    interface{}
+	Extends     []interface{}
+}
+
+type RulesSet map[string]interface{}
@@ -0,0 +1,158 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// argTagName is the struct tag used to request named arguments through the fields of a struct parameter.
+const argTagName = "structsconv"
+
+// ErrNamedArgNotFound is returned when a rule requests a named argument that was not passed to Map.
+var ErrNamedArgNotFound = errors.New("named argument not found")
+
+// NamedArg is an argument identified by its name instead of its type, see Arg.
+type NamedArg struct {
+	name  string
+	value interface{}
+}
+
+// Arg creates a named argument, to be passed to Map like any other argument.
+//
+// Named arguments are not injected by type, a rule function requests them by name with a Key
+// or with a struct parameter whose fields are tagged with `structsconv:"name"`.
+func Arg(name string, value interface{}) NamedArg {
+	return NamedArg{name: name, value: value}
+}
+
+// Args gives access to the named arguments, it can be requested by a rule function.
+type Args struct {
+	named map[string]interface{}
+}
+
+// Lookup returns the value of the named argument and whether it was passed.
+func (a Args) Lookup(name string) (interface{}, bool) {
+	v, ok := a.named[name]
+	return v, ok
+}
+
+// Key identifies a named argument of type T, e.g. Key[string]("tenant").
+type Key[T any] string
+
+// From returns the value of the named argument from args.
+//
+// Returns an error wrapping ErrNamedArgNotFound if the argument was not passed, or an error if it is not of type T.
+func (k Key[T]) From(args Args) (T, error) {
+	var zero T
+	v, ok := args.Lookup(string(k))
+	if !ok {
+		return zero, fmt.Errorf("%w: '%s'", ErrNamedArgNotFound, string(k))
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("named argument '%s' is of type '%T', expected '%s'", string(k), v, reflect.TypeOf(&zero).Elem())
+	}
+	return t, nil
+}
+
+var (
+	namedArgType = reflect.TypeOf(NamedArg{})
+	argsType     = reflect.TypeOf(Args{})
+)
+
+// splitArgs separates the named arguments from the arguments injected by type.
+func splitArgs(args []interface{}) ([]interface{}, map[string]interface{}, error) {
+	var typed []interface{}
+	var named map[string]interface{}
+	for _, a := range args {
+		na, ok := a.(NamedArg)
+		if !ok {
+			typed = append(typed, a)
+			continue
+		}
+		if named == nil {
+			named = make(map[string]interface{})
+		}
+		if _, exists := named[na.name]; exists {
+			return nil, nil, fmt.Errorf("rules error: named argument '%s' passed more than once", na.name)
+		}
+		named[na.name] = na.value
+	}
+	return typed, named, nil
+}
+
+// argTag is the parsed `structsconv:"name[,optional]"` tag of a field of a named arguments struct.
+type argTag struct {
+	name     string
+	optional bool
+}
+
+// parseArgTag parses the structsconv tag of a field, ok is false if the field is not tagged.
+func parseArgTag(f reflect.StructField) (argTag, bool) {
+	tag, ok := f.Tag.Lookup(argTagName)
+	if !ok {
+		return argTag{}, false
+	}
+	parts := strings.Split(tag, ",")
+	t := argTag{name: parts[0]}
+	for _, opt := range parts[1:] {
+		if opt == "optional" {
+			t.optional = true
+		}
+	}
+	return t, true
+}
+
+// isNamedArgsStruct returns true if t is a struct with at least one field tagged with `structsconv:"name"`.
+func isNamedArgsStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if named, ok := namedArgsStructs.Load(t); ok {
+		return named.(bool)
+	}
+	named := false
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := parseArgTag(t.Field(i)); ok {
+			named = true
+			break
+		}
+	}
+	namedArgsStructs.Store(t, named)
+	return named
+}
+
+// namedArgsStructs caches the result of isNamedArgsStruct by struct type, it is checked on every rule call.
+var namedArgsStructs sync.Map
+
+// buildNamedArgsStruct creates a value of the struct type t with its tagged fields set from the named arguments.
+func buildNamedArgsStruct(t reflect.Type, named map[string]interface{}) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := parseArgTag(t.Field(i))
+		if !ok {
+			continue
+		}
+		arg, exists := named[tag.name]
+		if !exists {
+			if tag.optional {
+				continue
+			}
+			return reflect.Value{}, fmt.Errorf("%w: '%s'", ErrNamedArgNotFound, tag.name)
+		}
+		av := reflect.ValueOf(arg)
+		if !av.IsValid() {
+			continue
+		}
+		if !av.Type().AssignableTo(t.Field(i).Type) {
+			return reflect.Value{}, fmt.Errorf(
+				"named argument '%s' is of type '%s', field '%s' expects '%s'", tag.name, av.Type(), t.Field(i).Name, t.Field(i).Type,
+			)
+		}
+		v.Field(i).Set(av)
+	}
+	return v, nil
+}
@@ -0,0 +1,77 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testTenantArgs struct {
+	Tenant string `structsconv:"tenant"`
+	Locale string `structsconv:"locale,optional"`
+}
+
+func Test_Key_From(t *testing.T) {
+	args := Args{named: map[string]interface{}{"tenant": "acme", "retries": 3}}
+
+	if got, err := Key[string]("tenant").From(args); err != nil || got != "acme" {
+		t.Errorf("Key.From() = %v, %v, want %v", got, err, "acme")
+	}
+	if _, err := Key[string]("locale").From(args); !errors.Is(err, ErrNamedArgNotFound) {
+		t.Errorf("Key.From() error = %v, want %v", err, ErrNamedArgNotFound)
+	}
+	if _, err := Key[string]("retries").From(args); err == nil || !strings.Contains(err.Error(), "is of type 'int', expected 'string'") {
+		t.Errorf("Key.From() error = %v, want type error", err)
+	}
+}
+
+func Test_splitArgs(t *testing.T) {
+	typed, named, err := splitArgs([]interface{}{1, Arg("tenant", "acme"), "str", Arg("locale", "es")})
+	if err != nil {
+		t.Fatalf("splitArgs() error = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(typed, []interface{}{1, "str"}) {
+		t.Errorf("splitArgs() typed = %v, want %v", typed, []interface{}{1, "str"})
+	}
+	if !reflect.DeepEqual(named, map[string]interface{}{"tenant": "acme", "locale": "es"}) {
+		t.Errorf("splitArgs() named = %v", named)
+	}
+
+	if _, _, err := splitArgs([]interface{}{Arg("tenant", "a"), Arg("tenant", "b")}); err == nil {
+		t.Errorf("splitArgs() error = nil, want duplicated named argument error")
+	}
+}
+
+func Test_Map_named_args(t *testing.T) {
+	type source struct{ Name string }
+	type target struct {
+		Name   string
+		Tenant string
+		Locale string
+	}
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules: RulesSet{
+			"Tenant": func(a Args) (string, error) { return Key[string]("tenant").From(a) },
+			"Locale": func(a testTenantArgs, s string) string { return a.Tenant + "/" + a.Locale + "/" + s },
+		},
+	})
+
+	d := &target{}
+	if err := MapE(&source{Name: "name"}, d, Arg("locale", "es"), "str", Arg("tenant", "acme")); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+	want := &target{Name: "name", Tenant: "acme", Locale: "acme/es/str"}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("MapE() = %v, want %v", d, want)
+	}
+
+	err := MapE(&source{Name: "name"}, &target{}, Arg("locale", "es"))
+	var me *MappingError
+	if !errors.Is(err, ErrNamedArgNotFound) || !errors.As(err, &me) || me.Path != "Tenant" {
+		t.Errorf("MapE() error = %v, want %v at 'Tenant'", err, ErrNamedArgNotFound)
+	}
+}
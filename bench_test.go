@@ -0,0 +1,83 @@
+package structsconv
+
+import (
+	"strconv"
+	"testing"
+)
+
+type benchItemSource struct {
+	ID    int
+	Name  string
+	Price float64
+}
+
+type benchItemTarget struct {
+	ID    int
+	Title string
+	Price float64
+	Code  string
+}
+
+type benchSource struct {
+	UserID int
+	Name   string
+	Email  string
+	Items  []benchItemSource
+}
+
+type benchTarget struct {
+	ID    int
+	Name  string
+	Email string
+	Items []benchItemTarget
+}
+
+type benchPrefix string
+
+func registerBenchRules(b *testing.B) {
+	withRegistry(b,
+		RulesDefinition{
+			Source: benchSource{},
+			Target: benchTarget{},
+			Rules:  RulesSet{"ID": "UserID"},
+		},
+		RulesDefinition{
+			Source: benchItemSource{},
+			Target: benchItemTarget{},
+			Rules: RulesSet{
+				"Title": "Name",
+				"Code": func(s benchItemSource, p benchPrefix) string {
+					return string(p) + strconv.Itoa(s.ID)
+				},
+			},
+		},
+	)
+}
+
+func newBenchSource(n int) benchSource {
+	src := benchSource{UserID: 1, Name: "john", Email: "john@doe.org", Items: make([]benchItemSource, n)}
+	for i := range src.Items {
+		src.Items[i] = benchItemSource{ID: i, Name: "item", Price: 1.5}
+	}
+	return src
+}
+
+func benchmarkMap(b *testing.B, n int) {
+	registerBenchRules(b)
+	m := New(WithLogger(nil))
+	src := newBenchSource(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var target benchTarget
+		if err := m.MapE(&src, &target, benchPrefix("c-")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMap_struct(b *testing.B) { benchmarkMap(b, 0) }
+
+func BenchmarkMap_slice100(b *testing.B) { benchmarkMap(b, 100) }
+
+func BenchmarkMap_slice1000(b *testing.B) { benchmarkMap(b, 1000) }
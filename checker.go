@@ -75,25 +75,64 @@ func checkRootValuesTypes(st, tt reflect.Value) error {
 	return nil
 }
 
+// checkRootAnyValuesTypes checks if the ROOT source and target types are valid for MapAny, with the implementations
+// of the registry r.
+func checkRootAnyValuesTypes(r *registry, st, tt reflect.Value) error {
+	if st.Kind() != reflect.Ptr || st.IsNil() {
+		return fmt.Errorf("rules error: source must be a pointer")
+	}
+	if tt.Kind() != reflect.Ptr || tt.IsNil() {
+		return fmt.Errorf("rules error: target must be a pointer")
+	}
+	if st.Elem().Kind() == reflect.Struct && tt.Elem().Kind() == reflect.Struct {
+		return nil
+	}
+	switch getMappingType(r, st.Elem(), tt.Elem()) {
+	case slicesMapping, arraysMapping, mapsMapping, directMapping:
+		return nil
+	default:
+		return fmt.Errorf(
+			"rules error: source and target must be pointers to structs, or to slices, arrays or maps of structs, got (%s) to (%s)",
+			st.Type(), tt.Type(),
+		)
+	}
+}
+
 // checkMapperRules checks if the mapper rules are valid
 func checkMapperRules(key rulesKey, rules RulesSet) {
-	log.Printf("Checking rules for mapping (%s -> %s).\n", key.source.String(), key.target.String())
+	checkStrategyRules(key, rules, nil)
+}
+
+// checkStrategyRules checks if the mapper rules are valid, the fields with a setter in the strategy
+// are checked against the type of the setter argument.
+func checkStrategyRules(key rulesKey, rules RulesSet, strategy *rulesStrategy) {
+	logCheckingRules(key)
 	for k, r := range rules {
 		if r == nil { // nil rule == ignore field
-			log.Printf(
-				"INFO: (%s -> %s) Field '%s' is marked as ignored.\n",
-				key.source.String(), key.target.String(), k,
-			)
+			logIgnoredField(key, k)
+			continue
+		}
+		if key.target != mapAnyType { // map keys are not checked
+			checkTargetKeyName(k, key)
+		}
+
+		// mapping target field value from the source struct, by a discriminator field (not supported by ToMap and FromMap)
+		if rule, ok := r.(SwitchRule); ok && key.source != mapAnyType && key.target != mapAnyType {
+			checkSwitch(rule, k, key)
 			continue
 		}
-		checkTargetKeyName(k, key)
+
+		var tt reflect.Type // any type can be set to a map value
+		if key.target != mapAnyType {
+			tt = strategy.fieldType(key, k)
+		}
 
 		t := reflect.TypeOf(r)
 		switch t.Kind() {
 		case reflect.String: // mapping source field name
-			checkMappingName(r.(string), k, key)
+			checkMappingName(r.(string), k, key, tt)
 		case reflect.Func: // mapping target field value from function
-			checkFunc(t, k, key)
+			checkFunc(t, k, key, tt)
 		default: // not valid rule
 			log.Panicf(
 				"ERROR: (%s -> %s) Rule '%s' is not valid. Rule = %v.\n",
@@ -117,7 +156,10 @@ func checkTargetKeyName(ruleKeyValue string, key rulesKey) {
 // checkMappingName checks field MappingName in source struct
 //	- MappingName is present in source struct
 // 	- field kind is the same in origin and target struct
-func checkMappingName(mappingName, ruleKey string, key rulesKey) {
+func checkMappingName(mappingName, ruleKey string, key rulesKey, targetType reflect.Type) {
+	if key.source == mapAnyType { // map keys are not checked
+		return
+	}
 	sf, exist := key.source.FieldByName(mappingName)
 	if !exist { // checks if MappingName is present in origin struct
 		log.Panicf(
@@ -125,34 +167,89 @@ func checkMappingName(mappingName, ruleKey string, key rulesKey) {
 			key.source.String(), key.target.String(), mappingName, key.source.String(),
 		)
 	}
-	tf, _ := key.target.FieldByName(ruleKey)
+	if targetType == nil { // any type can be set to a map value
+		return
+	}
 
 	switch {
-	case sf.Type.Kind() == reflect.Ptr && tf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == tf.Type.Elem().Kind(), // both are pointers to the same type
-		sf.Type.Kind() == reflect.Ptr && tf.Type.Kind() != reflect.Ptr && sf.Type.Elem().Kind() == tf.Type.Kind(), // source is pointer to the same type as target
-		sf.Type.Kind() != reflect.Ptr && tf.Type.Kind() == reflect.Ptr && sf.Type.Kind() == tf.Type.Elem().Kind(), // target is pointer to the same type as source
-		sf.Type.Kind() == tf.Type.Kind(): // both are the same type
+	case sf.Type.Kind() == reflect.Ptr && targetType.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == targetType.Elem().Kind(), // both are pointers to the same type
+		sf.Type.Kind() == reflect.Ptr && targetType.Kind() != reflect.Ptr && sf.Type.Elem().Kind() == targetType.Kind(), // source is pointer to the same type as target
+		sf.Type.Kind() != reflect.Ptr && targetType.Kind() == reflect.Ptr && sf.Type.Kind() == targetType.Elem().Kind(), // target is pointer to the same type as source
+		sf.Type.Kind() == targetType.Kind(): // both are the same type
 		return
 	default:
 		log.Panicf(
 			"ERROR: (%s -> %s) Field '%s' has different type in source (%s:%s) and target (%s:%s) structs.\n",
-			key.source.String(), key.target.String(), ruleKey, mappingName, sf.Type.String(), ruleKey, tf.Type.String(),
+			key.source.String(), key.target.String(), ruleKey, mappingName, sf.Type.String(), ruleKey, targetType.String(),
 		)
 	}
 }
 
 // checkFunc checks if function is valid according to the following criteria:
 //	- the function returns a value of the same type as the target
-func checkFunc(f reflect.Type, ruleKey string, key rulesKey) {
-	// checks if the function returns a value of the same type as the target
-	if getFieldByName(ruleKey, key.target).Type != f.Out(0) {
+//	- the function optionally returns an error as second value
+//	- the function does not request empty interface parameters, they would match every argument
+//	- the tagged fields of named arguments struct parameters are exported and have a name
+func checkFunc(f reflect.Type, ruleKey string, key rulesKey, targetType reflect.Type) {
+	checkFuncParams(f, ruleKey, key)
+	checkFuncResults(f, ruleKey, key, targetType)
+}
+
+// checkFuncParams checks the parameters of a rule function, see checkFunc.
+func checkFuncParams(f reflect.Type, ruleKey string, key rulesKey) {
+	// checks the named arguments struct parameters
+	for i := 0; i < f.NumIn(); i++ {
+		if isNamedArgsStruct(f.In(i)) {
+			checkNamedArgsStruct(f.In(i), ruleKey, key)
+		}
+	}
+
+	// checks if the function requests an empty interface, which is always ambiguous
+	for i := 0; i < f.NumIn(); i++ {
+		if f.In(i).Kind() == reflect.Interface && f.In(i).NumMethod() == 0 {
+			log.Panicf(
+				"ERROR: (%s -> %s) Function '%s' requests an empty interface in position %d, it would match every argument. Function = '%s'.\n",
+				key.source.String(), key.target.String(), ruleKey, i+1, f.String(),
+			)
+		}
+	}
+}
+
+// checkFuncResults checks the results of a rule function, see checkFunc.
+func checkFuncResults(f reflect.Type, ruleKey string, key rulesKey, targetType reflect.Type) {
+	// checks if the function returns one value, or a value and an error
+	if f.NumOut() == 0 || f.NumOut() > 2 || (f.NumOut() == 2 && f.Out(1) != errorType) {
+		log.Panicf(
+			"ERROR: (%s -> %s) Function '%s' must return a value of the target type, optionally followed by an error. Function = '%s'.\n",
+			key.source.String(), key.target.String(), ruleKey, f.String(),
+		)
+	}
+
+	// checks if the function returns a value of the same type as the target, any type can be set to a map value
+	if targetType != nil && targetType != f.Out(0) {
 		log.Panicf(
 			"ERROR: (%s -> %s) Function '%s' must return type '%s', currently returns '%s'. Function = '%s'.\n",
-			key.source.String(), key.target.String(), ruleKey, getFieldByName(ruleKey, key.target).Type.String(), f.Out(0).String(), f.String(),
+			key.source.String(), key.target.String(), ruleKey, targetType.String(), f.Out(0).String(), f.String(),
 		)
 	}
 }
 
+// checkNamedArgsStruct checks that the tagged fields of a named arguments struct are exported and have a name
+func checkNamedArgsStruct(t reflect.Type, ruleKey string, key rulesKey) {
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := parseArgTag(t.Field(i))
+		if !ok {
+			continue
+		}
+		if !t.Field(i).IsExported() || tag.name == "" {
+			log.Panicf(
+				"ERROR: (%s -> %s) Function '%s' requests named argument field '%s' of '%s', it must be exported and have a name.\n",
+				key.source.String(), key.target.String(), ruleKey, t.Field(i).Name, t.String(),
+			)
+		}
+	}
+}
+
 // getFieldByName returns field by name
 func getFieldByName(n string, t reflect.Type) reflect.StructField {
 	f, _ := t.FieldByName(n)
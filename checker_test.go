@@ -106,6 +106,38 @@ func Test_checkMapperRules_panics(t *testing.T) {
 				"fieldT1": func() int { return 314 },
 			},
 		},
+		{
+			name:         "Custom function returns no value,panic expected",
+			wantContains: "Function 'fieldT1' must return a value of the target type, optionally followed by an error",
+			rules: RulesSet{
+				"fieldT1": func() {},
+			},
+		},
+		{
+			name:         "Custom function second return value is not an error,panic expected",
+			wantContains: "Function 'fieldT1' must return a value of the target type, optionally followed by an error",
+			rules: RulesSet{
+				"fieldT1": func() (string, int) { return "", 0 },
+			},
+		},
+		{
+			name:         "Custom function requests empty interface,panic expected",
+			wantContains: "Function 'fieldT1' requests an empty interface in position 1",
+			rules: RulesSet{
+				"fieldT1": func(v interface{}) string { return "" },
+			},
+		},
+		{
+			name:         "Custom function requests unexported named argument,panic expected",
+			wantContains: "Function 'fieldT1' requests named argument field 'tenant'",
+			rules: RulesSet{
+				"fieldT1": func(a struct {
+					tenant string `structsconv:"tenant"`
+				}) string {
+					return a.tenant
+				},
+			},
+		},
 		{
 			name:         "Not valid rule, int value,panic expected",
 			wantContains: "Rule 'fieldT1' is not valid",
@@ -0,0 +1,126 @@
+package structsconv
+
+import (
+	"context"
+	"log"
+	"reflect"
+)
+
+// Clone returns an independent deep copy of v using the default Mapper with deep copy enabled,
+// see WithDeepCopy. Clone panics if the copy fails, use CloneE to get the error instead.
+func Clone[T any](v T) T {
+	c, err := CloneE(v)
+	if err != nil {
+		log.Panicf("ERROR: %s", err)
+	}
+	return c
+}
+
+// CloneE returns an independent deep copy of v using the default Mapper with deep copy enabled, see WithDeepCopy.
+//
+// The rules registered for a struct type to the same struct type are applied, but not the AfterMap() and Validate()
+// methods of the copied values. Shared references and pointer cycles are preserved in the copy, unless the default
+// Mapper disallows cycles, see WithCycles.
+func CloneE[T any](v T) (T, error) {
+	m := *defaultMapper()
+	m.deepCopy = true
+
+	var c T
+	sourceV := reflect.ValueOf(&v)
+	targetV := reflect.ValueOf(&c)
+	state, err := m.newState(context.Background(), sourceV, nil)
+	if err != nil {
+		return c, err
+	}
+	state.cloning = true
+	err = state.run(func() error {
+		return deepCopyValue(sourceV.Elem(), targetV.Elem(), state)
+	})
+	return c, err
+}
+
+// deepCopyValue copies source into target, which have the same type, allocating new slices, maps and pointees.
+func deepCopyValue(source, target reflect.Value, state *mappingState) error {
+	if !source.CanInterface() && source.CanAddr() {
+		source = getUnexportedField(source)
+	}
+	if !target.CanInterface() && target.CanAddr() {
+		target = getUnexportedField(target)
+	}
+
+	switch source.Kind() {
+	case reflect.Struct:
+		return structToStruct(source, target, source, state)
+	case reflect.Ptr:
+		if source.IsNil() {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		return mapPointee(source, target, state, func(s, t reflect.Value) error {
+			return deepCopyValue(s, t, state)
+		})
+	case reflect.Slice:
+		if source.IsNil() {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		s := reflect.MakeSlice(source.Type(), source.Len(), source.Len())
+		if err := deepCopyItems(source, s, state); err != nil {
+			return err
+		}
+		target.Set(s)
+	case reflect.Array:
+		return deepCopyItems(source, target, state)
+	case reflect.Map:
+		if source.IsNil() {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		m := reflect.MakeMapWithSize(source.Type(), source.Len())
+		for _, key := range source.MapKeys() {
+			// map items are not addressable, copy them so their unexported fields can be read
+			item := reflect.New(source.Type().Elem()).Elem()
+			item.Set(source.MapIndex(key))
+			itemCopy := reflect.New(source.Type().Elem()).Elem()
+			state.pushKey(key)
+			err := deepCopyValue(item, itemCopy, state)
+			state.pop()
+			if err != nil {
+				return wrapKeyError(key, err)
+			}
+			m.SetMapIndex(key, itemCopy)
+		}
+		target.Set(m)
+	case reflect.Interface:
+		if source.IsNil() {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		item := reflect.New(source.Elem().Type()).Elem()
+		item.Set(source.Elem())
+		itemCopy := reflect.New(source.Elem().Type()).Elem()
+		if err := deepCopyValue(item, itemCopy, state); err != nil {
+			return err
+		}
+		target.Set(itemCopy)
+	default:
+		target.Set(source)
+	}
+	return nil
+}
+
+// deepCopyItems copies the items of the source slice or array into the target one, with the same length.
+func deepCopyItems(source, target reflect.Value, state *mappingState) error {
+	for i := 0; i < source.Len(); i++ {
+		if err := checkContext(state.ctx); err != nil {
+			return err
+		}
+		state.pushIndex(i)
+		err := deepCopyValue(source.Index(i), target.Index(i), state)
+		state.pop()
+		if err != nil {
+			return wrapIndexError(i, err)
+		}
+	}
+	return nil
+}
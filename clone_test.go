@@ -0,0 +1,84 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Clone_rules(t *testing.T) {
+	type user struct {
+		Name     string
+		Password string
+	}
+	withRegistry(t, RulesDefinition{
+		Source: user{},
+		Target: user{},
+		Rules: RulesSet{
+			"Password": nil,
+		},
+	})
+
+	got := Clone([]user{{Name: "john", Password: "secret"}})
+	if want := []user{{Name: "john"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Clone() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_CloneE_targetMethods(t *testing.T) {
+	src := team{Lead: member{Name: "john", Email: "John@Mail.com"}, Members: []member{{Name: "jane"}}}
+	got, err := CloneE(src)
+	if err != nil {
+		t.Fatalf("CloneE() error = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(got, src) {
+		t.Errorf("CloneE() = %+v, want %+v", got, src)
+	}
+}
+
+func Test_CloneE_cycle(t *testing.T) {
+	type node struct {
+		Value int
+		Next  *node
+	}
+	n := &node{Value: 1}
+	n.Next = &node{Value: 2, Next: n}
+
+	got, err := CloneE(n)
+	if err != nil {
+		t.Fatalf("CloneE() error = %v", err)
+	}
+	if got == n || got.Next == n.Next || got.Next.Next != got || got.Next.Value != 2 {
+		t.Errorf("CloneE() cycle not reproduced")
+	}
+
+	backup := defaultMapper()
+	defer SetDefaultMapper(backup)
+	SetDefaultMapper(New(WithCycles(false)))
+
+	_, err = CloneE(n)
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("CloneE() error = %v, want %v", err, ErrCycle)
+	}
+	var mErr *MappingError
+	if !errors.As(err, &mErr) || mErr.Path != "Next.Next" {
+		t.Errorf("CloneE() error = %v, want path 'Next.Next'", err)
+	}
+}
+
+func Test_Mapper_deepCopy(t *testing.T) {
+	type source struct{ Tags []string }
+	type target struct{ Tags []string }
+
+	for _, deepCopy := range []bool{true, false} {
+		src := source{Tags: []string{"a"}}
+		var got target
+		if err := New(WithDeepCopy(deepCopy)).MapE(&src, &got); err != nil {
+			t.Fatalf("MapE() error = %v", err)
+		}
+		got.Tags[0] = "changed"
+		if shared := src.Tags[0] == "changed"; shared == deepCopy {
+			t.Errorf("deepCopy = %t, source shared = %t", deepCopy, shared)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+//go:build !structsconv_safe
+
+package structsconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Clone(t *testing.T) {
+	type item struct {
+		Name string
+		tags []string
+	}
+	type node struct {
+		Value int
+		Next  *node
+	}
+	type source struct {
+		Names  []string
+		Items  []item
+		ByKey  map[string]item
+		Ptr    *int
+		Node   *node
+		Array  [2][]int
+		Any    interface{}
+		secret map[string]int
+	}
+
+	n := 5
+	src := source{
+		Names:  []string{"a", "b"},
+		Items:  []item{{Name: "i1", tags: []string{"t1"}}},
+		ByKey:  map[string]item{"k": {Name: "i2", tags: []string{"t2"}}},
+		Ptr:    &n,
+		Node:   &node{Value: 1, Next: &node{Value: 2}},
+		Array:  [2][]int{{1}, {2}},
+		Any:    []int{3},
+		secret: map[string]int{"s": 1},
+	}
+
+	got := Clone(src)
+	if !reflect.DeepEqual(src, got) {
+		t.Fatalf("Clone() = %+v, want %+v", got, src)
+	}
+
+	got.Names[0] = "changed"
+	got.Items[0].tags[0] = "changed"
+	got.ByKey["k"].tags[0] = "changed"
+	*got.Ptr = 10
+	got.Node.Next.Value = 10
+	got.Array[0][0] = 10
+	got.Any.([]int)[0] = 10
+	got.secret["s"] = 10
+
+	want := source{
+		Names:  []string{"a", "b"},
+		Items:  []item{{Name: "i1", tags: []string{"t1"}}},
+		ByKey:  map[string]item{"k": {Name: "i2", tags: []string{"t2"}}},
+		Ptr:    &n,
+		Node:   &node{Value: 1, Next: &node{Value: 2}},
+		Array:  [2][]int{{1}, {2}},
+		Any:    []int{3},
+		secret: map[string]int{"s": 1},
+	}
+	if n != 5 || !reflect.DeepEqual(src, want) {
+		t.Errorf("source changed through the clone, got %+v", src)
+	}
+}
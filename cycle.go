@@ -0,0 +1,156 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrCycle is returned when a pointer cycle is found and cycles are disallowed, see WithCycles.
+	ErrCycle = errors.New("pointer cycle")
+
+	// ErrMaxDepth is returned when the structs are nested deeper than the limit, see WithMaxDepth.
+	ErrMaxDepth = errors.New("max depth exceeded")
+)
+
+// pointerKey identifies a source pointer mapped to a target pointer type.
+// The source type distinguishes a struct from its first field, which have the same address.
+type pointerKey struct {
+	ptr    uintptr
+	source reflect.Type
+	target reflect.Type
+}
+
+// mappedPointers holds the source pointers mapped in a mapping call, it is shared by the parallel workers.
+type mappedPointers struct {
+	mu       sync.Mutex
+	pointers map[pointerKey]*mappedPointer
+	orders   int // last order given to the items of a parallel mapping, see mappingState.order
+}
+
+// mappedPointer is the target pointer mapped from a source pointer.
+type mappedPointer struct {
+	target reflect.Value
+	owner  *mappingState // the state still mapping the pointee, reaching it again from the owner is a cycle
+	order  int           // order of the item that mapped the pointee
+	done   chan struct{} // closed when the pointee is mapped, only for the pointees mapped by parallel workers
+}
+
+// newPointerKey returns the key of the source pointer mapped to the target type,
+// and false for the pointers to zero size values, which may share their address.
+func newPointerKey(source reflect.Value, target reflect.Type) (pointerKey, bool) {
+	if source.Type().Elem().Size() == 0 {
+		return pointerKey{}, false
+	}
+	return pointerKey{source.Pointer(), source.Type(), target}, true
+}
+
+// addPointer records the target pointer mapped from the source pointer, whose pointee is being mapped by the state.
+func (s *mappingState) addPointer(source, target reflect.Value) *mappedPointer {
+	p := &mappedPointer{target: target, owner: s, order: s.order}
+	key, ok := newPointerKey(source, target.Type())
+	if !ok {
+		return p
+	}
+	if s.forkedOf != nil {
+		p.done = make(chan struct{})
+	}
+	s.pointers.mu.Lock()
+	defer s.pointers.mu.Unlock()
+	s.pointers.pointers[key] = p
+	return p
+}
+
+// donePointer marks the pointee of p as mapped.
+func (s *mappingState) donePointer(p *mappedPointer) {
+	s.pointers.mu.Lock()
+	defer s.pointers.mu.Unlock()
+	p.owner = nil
+	if p.done != nil {
+		close(p.done)
+	}
+}
+
+// mappedPointer returns the target pointer already mapped from the source pointer to the target type, or nil, and
+// whether reaching it is a cycle.
+//
+// The pointees shared by the items of a parallel mapping are mapped by the first item reaching them in order, as in
+// a sequential mapping: when the pointee was mapped by a later item, the state waits for it to be done and takes it
+// over, remap is then true and the pointee must be mapped again by the state. The wait ends with ctx.Err() when the
+// context is done.
+func (s *mappingState) mappedPointer(source reflect.Value, target reflect.Type) (p *mappedPointer, cycle, remap bool, err error) {
+	key, ok := newPointerKey(source, target)
+	if !ok {
+		return nil, false, false, nil
+	}
+	s.pointers.mu.Lock()
+	defer s.pointers.mu.Unlock()
+	for {
+		p, exists := s.pointers.pointers[key]
+		if !exists {
+			return nil, false, false, nil
+		}
+		if p.order <= s.order {
+			return p, p.owner != nil && s.descendsFrom(p.owner), false, nil
+		}
+		if p.owner == nil {
+			p.owner, p.order, p.done = s, s.order, make(chan struct{})
+			return p, false, true, nil
+		}
+		done := p.done
+		s.pointers.mu.Unlock()
+		select {
+		case <-done:
+		case <-s.ctx.Done():
+			s.pointers.mu.Lock()
+			return nil, false, false, s.ctx.Err()
+		}
+		s.pointers.mu.Lock()
+	}
+}
+
+// mapPointee sets the target to a new pointer whose pointee is mapped from the source pointee with mapFunc.
+//
+// A source pointer already mapped to the same target type is not mapped again, the target is set to the same target
+// pointer instead, so shared references and cycles are preserved.
+//
+// The pointee is marked as mapped even if mapFunc panics, so the parallel workers waiting for it do not block.
+func mapPointee(source, target reflect.Value, state *mappingState, mapFunc func(s, t reflect.Value) error) error {
+	p, cycle, remap, err := state.mappedPointer(source, target.Type())
+	if err != nil {
+		return err
+	}
+	if p != nil {
+		if cycle && state.mapper.disallowCycles {
+			return ErrCycle
+		}
+		target.Set(p.target)
+		if !remap {
+			return nil
+		}
+		defer state.donePointer(p)
+		p.target.Elem().SetZero()
+		return mapFunc(source.Elem(), p.target.Elem())
+	}
+
+	nv := reflect.New(target.Type().Elem())
+	target.Set(nv)
+	p = state.addPointer(source, nv)
+	defer state.donePointer(p)
+	return mapFunc(source.Elem(), nv.Elem())
+}
+
+// enterStruct checks the nesting depth before mapping a struct, it must be followed by a call to leaveStruct.
+func (s *mappingState) enterStruct() error {
+	s.depth++
+	if max := s.mapper.maxDepth; max > 0 && s.depth > max {
+		return ErrMaxDepth
+	}
+	return nil
+}
+
+// leaveStruct ends the mapping of a struct started by enterStruct.
+func (s *mappingState) leaveStruct() {
+	s.depth--
+}
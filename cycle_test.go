@@ -0,0 +1,117 @@
+package structsconv
+
+import (
+	"errors"
+	"testing"
+)
+
+type cycleNodeSource struct {
+	Name     string
+	Parent   *cycleNodeSource
+	Children []*cycleNodeSource
+}
+
+type cycleNodeTarget struct {
+	Name     string
+	Parent   *cycleNodeTarget
+	Children []*cycleNodeTarget
+}
+
+func newCycleTree() *cycleNodeSource {
+	root := &cycleNodeSource{Name: "root"}
+	for _, name := range []string{"a", "b"} {
+		root.Children = append(root.Children, &cycleNodeSource{Name: name, Parent: root})
+	}
+	return root
+}
+
+func Test_Map_cycles(t *testing.T) {
+	root := newCycleTree()
+	var got cycleNodeTarget
+	if err := MapE(root, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+
+	if len(got.Children) != 2 {
+		t.Fatalf("children = %d, want 2", len(got.Children))
+	}
+	for i, child := range got.Children {
+		if child.Name != root.Children[i].Name {
+			t.Errorf("Children[%d].Name = %s, want %s", i, child.Name, root.Children[i].Name)
+		}
+		if child.Parent != &got {
+			t.Errorf("Children[%d].Parent is not the root target", i)
+		}
+	}
+}
+
+func Test_Map_sharedReferences(t *testing.T) {
+	type itemSource struct{ Name string }
+	type itemTarget struct{ Name string }
+	type source struct {
+		First  *itemSource
+		Second *itemSource
+		Other  *itemSource
+	}
+	type target struct {
+		First  *itemTarget
+		Second *itemTarget
+		Other  *itemTarget
+	}
+
+	shared := &itemSource{Name: "shared"}
+	src := source{First: shared, Second: shared, Other: &itemSource{Name: "shared"}}
+	var got target
+	if err := MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if got.First != got.Second {
+		t.Errorf("First and Second must point to the same target")
+	}
+	if got.First == got.Other {
+		t.Errorf("First and Other must point to different targets")
+	}
+}
+
+func Test_Map_cycles_disallowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapper  *Mapper
+		wantErr error
+		path    string
+	}{
+		{
+			name:    "cycles disallowed",
+			mapper:  New(WithCycles(false)),
+			wantErr: ErrCycle,
+			path:    "Children[0].Parent",
+		},
+		{
+			name:    "max depth",
+			mapper:  New(WithMaxDepth(1)),
+			wantErr: ErrMaxDepth,
+			path:    "Children[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got cycleNodeTarget
+			err := tt.mapper.MapE(newCycleTree(), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MapE() error = %v, want %v", err, tt.wantErr)
+			}
+			var mErr *MappingError
+			if !errors.As(err, &mErr) || mErr.Path != tt.path {
+				t.Errorf("MapE() error = %v, want path '%s'", err, tt.path)
+			}
+		})
+	}
+}
+
+func Test_Map_maxDepth(t *testing.T) {
+	var got cycleNodeTarget
+	if err := New(WithMaxDepth(2)).MapE(newCycleTree(), &got); err != nil {
+		t.Errorf("MapE() error = %v", err)
+	}
+}
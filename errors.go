@@ -0,0 +1,58 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPanic is wrapped by the error returned when a panic occurs during the mapping.
+var ErrPanic = errors.New("panic during mapping")
+
+// MappingError is returned when the mapping of a target field fails.
+//
+// Path is the path of the target field from the root struct, e.g. "Info.Addresses[0].Zip".
+type MappingError struct {
+	Path string
+	Err  error
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("mapping error: field '%s': %s", e.Path, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// wrapFieldError prepends the target field name to the path of the error.
+func wrapFieldError(fieldName string, err error) error {
+	return prependPath(fieldName, err)
+}
+
+// wrapIndexError prepends the index of a slice or array item to the path of the error.
+func wrapIndexError(i int, err error) error {
+	return prependPath(fmt.Sprintf("[%d]", i), err)
+}
+
+// wrapKeyError prepends the key of a map item to the path of the error.
+func wrapKeyError(key interface{}, err error) error {
+	return prependPath(fmt.Sprintf("[%v]", key), err)
+}
+
+// prependPath prepends a segment to the path of a *MappingError, or wraps err in a new one.
+func prependPath(segment string, err error) error {
+	me, ok := err.(*MappingError)
+	if !ok {
+		return &MappingError{Path: segment, Err: err}
+	}
+	switch {
+	case me.Path == "":
+		me.Path = segment
+	case strings.HasPrefix(me.Path, "["):
+		me.Path = segment + me.Path
+	default:
+		me.Path = segment + "." + me.Path
+	}
+	return me
+}
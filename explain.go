@@ -0,0 +1,192 @@
+package structsconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Strategy describes how a target field is filled.
+type Strategy string
+
+const (
+	StrategyName     Strategy = "name"     // the source field with the same name
+	StrategyRename   Strategy = "rename"   // the source field named by a rule
+	StrategyFunc     Strategy = "func"     // the value returned by a rule function
+	StrategySwitch   Strategy = "switch"   // the source struct mapped to a type chosen by a Switch rule
+	StrategyIgnored  Strategy = "ignored"  // the field is marked as ignored (nil rule)
+	StrategyUnmapped Strategy = "unmapped" // there is no rule nor source field for the field
+)
+
+// Plan describes how a source struct type is mapped to a target struct type, see Explain.
+type Plan struct {
+	Source      string      `json:"source"`
+	Target      string      `json:"target"`
+	Recursive   bool        `json:"recursive,omitempty"`   // the pair is already being described by an outer plan
+	Constructor string      `json:"constructor,omitempty"` // signature of the constructor building the target, if any
+	Fields      []FieldPlan `json:"fields,omitempty"`
+}
+
+// FieldPlan describes how a target field is filled.
+type FieldPlan struct {
+	Target   string   `json:"target"`           // name of the target field
+	Type     string   `json:"type"`             // type of the target field
+	Strategy Strategy `json:"strategy"`         // how the field is filled
+	Source   string   `json:"source,omitempty"` // name of the source field, or signature of the rule function
+	Kind     string   `json:"kind,omitempty"`   // kind of mapping between the source and target fields
+	Setter   string   `json:"setter,omitempty"` // name of the method that sets the field, if any
+	Warnings []string `json:"warnings,omitempty"`
+	Nested   *Plan    `json:"nested,omitempty"` // plan of the nested structs, items of slices, arrays and maps included
+}
+
+// Explain describes, without mapping any value, how the source struct type is mapped to the target struct type
+// using the registered rules. Source and target can be structs or pointers to structs.
+//
+// The plan is rendered as text by String and as JSON by encoding/json.
+func Explain(source, target any) (*Plan, error) {
+	st, tt := derefType(reflect.TypeOf(source)), derefType(reflect.TypeOf(target))
+	if st == nil || st.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rules error: source must be a struct or a pointer to a struct")
+	}
+	if tt == nil || tt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rules error: target must be a struct or a pointer to a struct")
+	}
+	return explainStructs(loadRegistry(), st, tt, make(map[rulesKey]bool), nil), nil
+}
+
+// explainStructs describes the mapping of every field of the target struct type, with the rules of the registry r.
+// When keys is not nil, the types of every plan are recorded in it.
+func explainStructs(r *registry, source, target reflect.Type, visited map[rulesKey]bool, keys map[*Plan]rulesKey) *Plan {
+	key := rulesKey{source, target}
+	plan := &Plan{Source: source.String(), Target: target.String()}
+	if keys != nil {
+		keys[plan] = key
+	}
+	if visited[key] {
+		plan.Recursive = true
+		return plan
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	strategy := r.strategies[key]
+	if strategy != nil && strategy.constructor.IsValid() {
+		plan.Constructor = strategy.constructor.Type().String()
+		return plan
+	}
+
+	rules := r.rules[key]
+	for i := 0; i < target.NumField(); i++ {
+		tf := target.Field(i)
+		fp := FieldPlan{Target: tf.Name, Type: tf.Type.String()}
+
+		rule, exists := rules[tf.Name]
+		sf, sExists := source.FieldByName(tf.Name)
+		if setter, ok := strategy.setter(tf.Name); ok { // the value is the setter argument
+			fp.Setter = setter.Name
+			tf.Type = setter.Type.In(1)
+			sf, sExists = source.FieldByName(setter.Name[len("Set"):])
+		}
+		switch {
+		case exists && rule == nil:
+			fp.Strategy = StrategyIgnored
+		case exists && reflect.TypeOf(rule).Kind() == reflect.String:
+			fp.Strategy = StrategyRename
+			fp.Source = rule.(string)
+			sf, _ = source.FieldByName(fp.Source)
+			explainFieldTypes(r, &fp, sf.Type, tf.Type, visited, keys)
+		case exists && reflect.TypeOf(rule) == reflect.TypeOf(SwitchRule{}):
+			fp.Strategy = StrategySwitch
+			fp.Source = rule.(SwitchRule).String()
+		case exists:
+			fp.Strategy = StrategyFunc
+			fp.Source = reflect.TypeOf(rule).String()
+		case sExists:
+			fp.Strategy = StrategyName
+			fp.Source = sf.Name
+			explainFieldTypes(r, &fp, sf.Type, tf.Type, visited, keys)
+		default:
+			fp.Strategy = StrategyUnmapped
+			fp.Warnings = append(fp.Warnings, "no mapping found in source")
+		}
+		plan.Fields = append(plan.Fields, fp)
+	}
+	return plan
+}
+
+// explainFieldTypes sets the kind of mapping, warnings and nested plan of a field mapped from a source field.
+func explainFieldTypes(r *registry, fp *FieldPlan, source, target reflect.Type, visited map[rulesKey]bool, keys map[*Plan]rulesKey) {
+	kind := getMappingType(r, planValue(source), planValue(target))
+	fp.Kind = kind.String()
+	if kind == ptrMapping {
+		source, target = derefType(source), derefType(target)
+		kind = getMappingType(r, planValue(source), planValue(target))
+	}
+
+	switch kind {
+	case incompatibleTypes:
+		fp.Warnings = append(fp.Warnings, fmt.Sprintf("incompatible types (%s) to (%s), field is ignored", source, target))
+	case structsMapping:
+		fp.Nested = explainStructs(r, source, target, visited, keys)
+	case slicesMapping, arraysMapping, mapsMapping:
+		if target.Elem().Kind() == reflect.Interface { // items mapped using the registered implementations
+			return
+		}
+		fp.Nested = explainStructs(r, derefType(source.Elem()), derefType(target.Elem()), visited, keys)
+	}
+}
+
+// planValue returns a value of type t to find out its kind of mapping, pointers are not nil.
+func planValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem())
+	}
+	return reflect.New(t).Elem()
+}
+
+// derefType returns the type pointed by t if t is a pointer.
+func derefType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// String renders the plan as an indented text, one line per target field.
+func (p *Plan) String() string {
+	var b strings.Builder
+	p.write(&b, "")
+	return b.String()
+}
+
+func (p *Plan) write(b *strings.Builder, indent string) {
+	fmt.Fprintf(b, "%s%s -> %s", indent, p.Source, p.Target)
+	if p.Recursive {
+		b.WriteString(" (recursive)\n")
+		return
+	}
+	if p.Constructor != "" {
+		fmt.Fprintf(b, ": constructor %s\n", p.Constructor)
+		return
+	}
+	b.WriteString("\n")
+	for _, f := range p.Fields {
+		fmt.Fprintf(b, "%s  %s %s: %s", indent, f.Target, f.Type, f.Strategy)
+		if f.Source != "" {
+			fmt.Fprintf(b, " %s", f.Source)
+		}
+		if f.Kind != "" {
+			fmt.Fprintf(b, " (%s)", f.Kind)
+		}
+		if f.Setter != "" {
+			fmt.Fprintf(b, " via %s", f.Setter)
+		}
+		b.WriteString("\n")
+		for _, w := range f.Warnings {
+			fmt.Fprintf(b, "%s    WARNING: %s\n", indent, w)
+		}
+		if f.Nested != nil {
+			f.Nested.write(b, indent+"    ")
+		}
+	}
+}
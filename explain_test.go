@@ -0,0 +1,128 @@
+package structsconv
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Explain(t *testing.T) {
+	type itemSource struct{ Title string }
+	type itemTarget struct {
+		Title string
+		Genre string
+	}
+	type node struct {
+		Name string
+		Next *node
+	}
+	type source struct {
+		UserID  int
+		Name    string
+		Age     string
+		Items   []itemSource
+		Node    node
+		Secret  string
+		Comment string
+	}
+	type target struct {
+		ID       int
+		Name     string
+		Age      int
+		Items    []*itemTarget
+		Node     node
+		Secret   string
+		FullName string
+		Missing  string
+	}
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules: RulesSet{
+			"ID":       "UserID",
+			"Secret":   nil,
+			"FullName": func(s source) string { return s.Name },
+		},
+	})
+
+	plan, err := Explain(source{}, &target{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v, want nil", err)
+	}
+
+	want := []FieldPlan{
+		{Target: "ID", Type: "int", Strategy: StrategyRename, Source: "UserID", Kind: "direct"},
+		{Target: "Name", Type: "string", Strategy: StrategyName, Source: "Name", Kind: "direct"},
+		{
+			Target: "Age", Type: "int", Strategy: StrategyName, Source: "Age", Kind: "incompatible",
+			Warnings: []string{"incompatible types (string) to (int), field is ignored"},
+		},
+		{
+			Target: "Items", Type: "[]*structsconv.itemTarget", Strategy: StrategyName, Source: "Items", Kind: "slices",
+			Nested: &Plan{
+				Source: "structsconv.itemSource",
+				Target: "structsconv.itemTarget",
+				Fields: []FieldPlan{
+					{Target: "Title", Type: "string", Strategy: StrategyName, Source: "Title", Kind: "direct"},
+					{Target: "Genre", Type: "string", Strategy: StrategyUnmapped, Warnings: []string{"no mapping found in source"}},
+				},
+			},
+		},
+		{Target: "Node", Type: "structsconv.node", Strategy: StrategyName, Source: "Node", Kind: "direct"},
+		{Target: "Secret", Type: "string", Strategy: StrategyIgnored},
+		{Target: "FullName", Type: "string", Strategy: StrategyFunc, Source: "func(structsconv.source) string"},
+		{Target: "Missing", Type: "string", Strategy: StrategyUnmapped, Warnings: []string{"no mapping found in source"}},
+	}
+	if !reflect.DeepEqual(plan.Fields, want) {
+		t.Errorf("Explain() = %+v, want %+v", plan.Fields, want)
+	}
+
+	text := plan.String()
+	for _, line := range []string{
+		"structsconv.source -> structsconv.target\n",
+		"  ID int: rename UserID (direct)\n",
+		"    WARNING: incompatible types (string) to (int), field is ignored\n",
+		"    structsconv.itemSource -> structsconv.itemTarget\n",
+		"      Genre string: unmapped\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Plan.String() = %q, want to contain %q", text, line)
+		}
+	}
+
+	b, err := json.Marshal(plan)
+	if err != nil || !strings.Contains(string(b), `{"target":"ID","type":"int","strategy":"rename","source":"UserID","kind":"direct"}`) {
+		t.Errorf("json.Marshal(plan) = %s, %v", b, err)
+	}
+}
+
+func Test_Explain_recursive(t *testing.T) {
+	type nodeSource struct {
+		Name string
+		Next *nodeSource
+	}
+	type nodeTarget struct {
+		Name string
+		Next *nodeTarget
+	}
+
+	plan, err := Explain(nodeSource{}, nodeTarget{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v, want nil", err)
+	}
+	next := plan.Fields[1]
+	if next.Kind != "ptr" || next.Nested == nil || !next.Nested.Recursive {
+		t.Errorf("Explain() Next = %+v, want recursive nested plan", next)
+	}
+}
+
+func Test_Explain_errors(t *testing.T) {
+	if _, err := Explain(1, struct{}{}); err == nil {
+		t.Errorf("Explain() error = nil, want source error")
+	}
+	if _, err := Explain(struct{}{}, "str"); err == nil {
+		t.Errorf("Explain() error = nil, want target error")
+	}
+}
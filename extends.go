@@ -0,0 +1,146 @@
+package structsconv
+
+import (
+	"log"
+	"reflect"
+)
+
+// resolveRules returns the rules of the definition merged with the rules of its bases, see RulesDefinition.Extends.
+//
+// The bases are merged in order, and the rules of the definition override them. Two bases with different rules for
+// the same target field are a conflict, unless the definition overrides the field.
+func resolveRules(key rulesKey, d RulesDefinition) RulesSet {
+	return resolveExtends(key, d, nil)
+}
+
+// resolveExtends resolves the rules of the definition, extending is the chain of base definitions being resolved,
+// to detect a definition extending itself.
+func resolveExtends(key rulesKey, d RulesDefinition, extending []*RulesDefinition) RulesSet {
+	if len(d.Extends) == 0 {
+		return d.Rules
+	}
+	merged := make(RulesSet)
+	for i, base := range d.Extends {
+		for k, r := range baseRules(key, base, i, extending) {
+			if prev, exists := merged[k]; exists && !sameRule(prev, r) {
+				if _, overridden := d.Rules[k]; !overridden {
+					log.Panicf(
+						"ERROR: (%s -> %s) Rule '%s' is defined differently by more than one base, override it in Rules.\n",
+						key.source.String(), key.target.String(), k,
+					)
+				}
+			}
+			merged[k] = r
+		}
+	}
+	for k, r := range d.Rules {
+		merged[k] = r
+	}
+	return merged
+}
+
+// baseRules returns the rules of a base of the definition, i is its position in Extends.
+func baseRules(key rulesKey, base interface{}, i int, extending []*RulesDefinition) RulesSet {
+	switch b := base.(type) {
+	case RulesSet:
+		return b
+	case RulesDefinition:
+		return adaptRules(key, b, extending)
+	case *RulesDefinition:
+		for _, d := range extending {
+			if d == b {
+				log.Panicf(
+					"ERROR: (%s -> %s) Extends element %d extends itself.\n",
+					key.source.String(), key.target.String(), i+1,
+				)
+			}
+		}
+		return adaptRules(key, *b, append(extending, b))
+	default:
+		log.Panicf(
+			"ERROR: (%s -> %s) Extends element %d must be a RulesSet or a RulesDefinition. Element = %v.\n",
+			key.source.String(), key.target.String(), i+1, base,
+		)
+		return nil
+	}
+}
+
+// adaptRules returns the rules of a base definition to be used with the source type of key.
+//
+// When the base definition has another source type with the same field shape, the parameters of the rule functions
+// of that type, or a pointer to it, receive the source converted to it.
+func adaptRules(key rulesKey, base RulesDefinition, extending []*RulesDefinition) RulesSet {
+	baseKey := buildKey(base.Source, base.Target)
+	rules := resolveExtends(baseKey, base, extending)
+	if baseKey.source == nil || baseKey.source == key.source {
+		return rules
+	}
+	if !key.source.ConvertibleTo(baseKey.source) {
+		log.Panicf(
+			"ERROR: (%s -> %s) Base definition (%s -> %s) has a source with a different shape, its rules cannot be reused.\n",
+			key.source.String(), key.target.String(), baseKey.source.String(), baseKey.target.String(),
+		)
+	}
+	adapted := make(RulesSet, len(rules))
+	for k, r := range rules {
+		if v := reflect.ValueOf(r); v.Kind() == reflect.Func {
+			r = adaptFunc(v, baseKey.source, key.source).Interface()
+		}
+		adapted[k] = r
+	}
+	return adapted
+}
+
+// adaptFunc returns a function like f, whose parameters of the type from, or a pointer to it, are of the type to,
+// and are converted to from before calling f.
+func adaptFunc(f reflect.Value, from, to reflect.Type) reflect.Value {
+	ft := f.Type()
+	in := make([]reflect.Type, ft.NumIn())
+	adapted := false
+	for i := range in {
+		switch in[i] = ft.In(i); in[i] {
+		case from:
+			in[i], adapted = to, true
+		case reflect.PointerTo(from):
+			in[i], adapted = reflect.PointerTo(to), true
+		}
+	}
+	if !adapted {
+		return f
+	}
+	out := make([]reflect.Type, ft.NumOut())
+	for i := range out {
+		out[i] = ft.Out(i)
+	}
+	return reflect.MakeFunc(reflect.FuncOf(in, out, ft.IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		for i := range args {
+			if in[i] != ft.In(i) {
+				args[i] = args[i].Convert(ft.In(i))
+			}
+		}
+		if ft.IsVariadic() {
+			return f.CallSlice(args)
+		}
+		return f.Call(args)
+	})
+}
+
+// sameRule reports whether two rules are the same: the same source field name or Switch.
+// Functions are never the same, closures of the same literal and adapted functions cannot be told apart.
+func sameRule(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Func:
+		return false
+	case reflect.String:
+		return va.String() == vb.String()
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
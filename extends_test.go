@@ -0,0 +1,167 @@
+package structsconv
+
+import (
+	"errors"
+	"testing"
+)
+
+type employeeDto struct {
+	UserID    string
+	FullName  string
+	CreatedBy string
+}
+
+type contractorDto struct {
+	UserID    string
+	FullName  string
+	CreatedBy string
+}
+
+type employee struct {
+	ID        string
+	Name      string
+	CreatedBy string
+}
+
+type contractor struct {
+	ID        string
+	Name      string
+	CreatedBy string
+}
+
+var (
+	identityRules = RulesSet{"ID": "UserID"}
+	auditRules    = RulesSet{"CreatedBy": func() string { return "system" }}
+)
+
+func Test_Map_extends(t *testing.T) {
+	own := RulesSet{"Name": "FullName", "CreatedBy": func(e *employeeDto) string { return "hr:" + e.CreatedBy }}
+	withRegistry(t, RulesDefinition{
+		Source:  employeeDto{},
+		Target:  employee{},
+		Extends: []interface{}{identityRules, auditRules},
+		Rules:   own,
+	})
+
+	var got employee
+	if err := New(WithLogger(nil)).MapE(&employeeDto{UserID: "u1", FullName: "Ann", CreatedBy: "bob"}, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := (employee{ID: "u1", Name: "Ann", CreatedBy: "hr:bob"}); got != want {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+	if len(own) != 2 {
+		t.Errorf("Rules of the definition modified: %v", own)
+	}
+}
+
+func Test_Map_extends_derivedTypes(t *testing.T) {
+	employeeDefinition := RulesDefinition{
+		Source:  employeeDto{},
+		Target:  employee{},
+		Extends: []interface{}{identityRules},
+		Rules: RulesSet{
+			"Name": func(e *employeeDto, prefix string) (string, error) {
+				if e.FullName == "" {
+					return "", errors.New("no name")
+				}
+				return prefix + e.FullName, nil
+			},
+		},
+	}
+	withRegistry(t,
+		employeeDefinition,
+		RulesDefinition{Source: contractorDto{}, Target: contractor{}, Extends: []interface{}{&employeeDefinition, auditRules}},
+	)
+
+	var got contractor
+	src := contractorDto{UserID: "u2", FullName: "Bob"}
+	if err := New(WithLogger(nil)).MapE(&src, &got, "ext "); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := (contractor{ID: "u2", Name: "ext Bob", CreatedBy: "system"}); got != want {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+
+	src.FullName = ""
+	var me *MappingError
+	if err := New(WithLogger(nil)).MapE(&src, &got, "ext "); !errors.As(err, &me) || me.Path != "Name" {
+		t.Errorf("MapE() error = %v, want path Name", err)
+	}
+}
+
+func Test_registerRules_extends_panics(t *testing.T) {
+	otherAudit := RulesSet{"CreatedBy": func() string { return "other" }}
+	auditBy := func(user string) RulesSet {
+		return RulesSet{"CreatedBy": func() string { return user }}
+	}
+	self := &RulesDefinition{Source: employeeDto{}, Target: employee{}}
+	self.Extends = []interface{}{identityRules, self}
+	tests := []struct {
+		name         string
+		definition   RulesDefinition
+		wantContains string
+	}{
+		{
+			name: "Bases with different rules for a field,panic expected",
+			definition: RulesDefinition{
+				Source: employeeDto{}, Target: employee{},
+				Extends: []interface{}{auditRules, otherAudit},
+			},
+			wantContains: "Rule 'CreatedBy' is defined differently by more than one base, override it in Rules",
+		},
+		{
+			name: "Bases with the same function for a field,panic expected",
+			definition: RulesDefinition{
+				Source: employeeDto{}, Target: employee{},
+				Extends: []interface{}{auditRules, auditRules},
+			},
+			wantContains: "Rule 'CreatedBy' is defined differently by more than one base, override it in Rules",
+		},
+		{
+			name: "Bases with closures of the same function literal for a field,panic expected",
+			definition: RulesDefinition{
+				Source: employeeDto{}, Target: employee{},
+				Extends: []interface{}{auditBy("ann"), auditBy("bob")},
+			},
+			wantContains: "Rule 'CreatedBy' is defined differently by more than one base, override it in Rules",
+		},
+		{
+			name:         "Base definition extending itself,panic expected",
+			definition:   RulesDefinition{Source: employeeDto{}, Target: employee{}, Extends: []interface{}{self}},
+			wantContains: "Extends element 2 extends itself",
+		},
+		{
+			name: "Invalid base,panic expected",
+			definition: RulesDefinition{
+				Source: employeeDto{}, Target: employee{},
+				Extends: []interface{}{map[string]interface{}{"ID": "UserID"}},
+			},
+			wantContains: "Extends element 1 must be a RulesSet or a RulesDefinition",
+		},
+		{
+			name: "Base definition with a source of another shape,panic expected",
+			definition: RulesDefinition{
+				Source: employeeDto{}, Target: employee{},
+				Extends: []interface{}{RulesDefinition{Source: purchaseDto{}, Target: employee{}, Rules: RulesSet{"ID": "ID"}}},
+			},
+			wantContains: "Base definition (structsconv.purchaseDto -> structsconv.employee) has a source with a different shape",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRegistry(t)
+			var f = func() { RegisterRulesDefinitions(tt.definition) }
+			assertPanic(f, tt.wantContains, t)
+		})
+	}
+
+	t.Run("Conflict overridden by Rules", func(t *testing.T) {
+		withRegistry(t, RulesDefinition{
+			Source: employeeDto{}, Target: employee{},
+			Extends: []interface{}{auditRules, otherAudit, auditRules},
+			Rules:   RulesSet{"CreatedBy": "CreatedBy"},
+		})
+	})
+}
@@ -0,0 +1,423 @@
+package structsconv_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rendis/structsconv"
+)
+
+type fzInner struct {
+	A int
+	B string
+	c int
+}
+
+type fzInnerMirror struct {
+	A int
+	B string
+	c int
+}
+
+// fzItem is the item of the maps, unexported fields of map items are not supported.
+type fzItem struct {
+	A int
+	B string
+}
+
+type fzItemMirror struct {
+	A int
+	B string
+}
+
+type fzShape struct {
+	I    int
+	S    string
+	F    float64
+	B    bool
+	P    *fzInner
+	N    fzInner
+	L    []fzInner
+	LP   []*fzInner
+	A    [3]fzInner
+	M    map[string]fzItem
+	Tags []string
+	u    int
+	us   string
+	ul   []fzInner
+	un   fzInner
+}
+
+type fzShapeMirror struct {
+	I    int
+	S    string
+	F    float64
+	B    bool
+	P    *fzInnerMirror
+	N    fzInnerMirror
+	L    []fzInnerMirror
+	LP   []*fzInnerMirror
+	A    [3]fzInnerMirror
+	M    map[string]fzItemMirror
+	Tags []string
+	u    int
+	us   string
+	ul   []fzInnerMirror
+	un   fzInnerMirror
+}
+
+type fzIncompatible struct {
+	I    string
+	S    int
+	F    []int
+	B    *int
+	P    int
+	N    []fzInnerMirror
+	L    [2]fzInnerMirror
+	LP   []fzInnerMirror
+	A    [5]fzInnerMirror
+	M    map[int]fzItemMirror
+	Tags []int
+	u    *fzInnerMirror
+	us   []string
+	ul   [1]fzInnerMirror
+	un   *int
+}
+
+// fzTree is a recursive shape, without cycles.
+type fzTree struct {
+	V     int
+	Name  string
+	Next  *fzTree
+	Kids  []fzTree
+	Items map[string]fzItem
+}
+
+type fzTreeMirror struct {
+	V     int
+	Name  string
+	Next  *fzTreeMirror
+	Kids  []fzTreeMirror
+	Items map[string]fzItemMirror
+}
+
+type fzTreeIncompatible struct {
+	V     []int
+	Name  *fzTreeIncompatible
+	Next  fzTreeMirror
+	Kids  map[int]fzTreeMirror
+	Items []fzItemMirror
+}
+
+// fzNested has embedded structs, other number kinds and nested collections.
+type fzNested struct {
+	fzItem
+	U8      uint8
+	I32     int32
+	U       uint
+	Opt     *string
+	PP      **fzInner
+	Bytes   []byte
+	Matrix  [][]fzItem
+	Groups  map[string][]fzItem
+	Indexed map[int]map[string]fzItem
+}
+
+type fzNestedMirror struct {
+	fzItem  // embedded fields are mapped by their type name
+	U8      uint8
+	I32     int32
+	U       uint
+	Opt     *string
+	PP      **fzInnerMirror
+	Bytes   []byte
+	Matrix  [][]fzItem // collections of collections of structs are only assigned, from the same type
+	Groups  map[string][]fzItem
+	Indexed map[int]map[string]fzItem
+}
+
+type fzNestedIncompatible struct {
+	fzItem  int
+	U8      string
+	I32     []int32
+	U       *uint
+	Opt     string
+	PP      *fzInnerMirror
+	Bytes   string
+	Matrix  []fzItemMirror
+	Groups  map[int][]fzItemMirror
+	Indexed map[int]fzItemMirror
+}
+
+// fuzzCase is a source shape built from the fuzz input, and the targets mapped from it.
+type fuzzCase struct {
+	source       func(r *byteReader) interface{}
+	targets      []func() interface{} // same shape targets, which must be equal to the source once mapped
+	incompatible []func() interface{} // targets whose fields cannot be mapped, which must not make the mapping panic
+}
+
+var fuzzCases = []fuzzCase{
+	{
+		source:       func(r *byteReader) interface{} { return r.shape() },
+		targets:      []func() interface{}{func() interface{} { return &fzShape{} }, func() interface{} { return &fzShapeMirror{} }},
+		incompatible: []func() interface{}{func() interface{} { return &fzIncompatible{} }},
+	},
+	{
+		source:       func(r *byteReader) interface{} { return r.tree(0) },
+		targets:      []func() interface{}{func() interface{} { return &fzTree{} }, func() interface{} { return &fzTreeMirror{} }},
+		incompatible: []func() interface{}{func() interface{} { return &fzTreeIncompatible{} }},
+	},
+	{
+		source:       func(r *byteReader) interface{} { return r.nested() },
+		targets:      []func() interface{}{func() interface{} { return &fzNested{} }, func() interface{} { return &fzNestedMirror{} }},
+		incompatible: []func() interface{}{func() interface{} { return &fzNestedIncompatible{} }},
+	},
+}
+
+// byteReader consumes the fuzz input to build values, returning zeros when it is exhausted.
+type byteReader struct{ data []byte }
+
+func (r *byteReader) next() byte {
+	if len(r.data) == 0 {
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *byteReader) int() int {
+	var buf [8]byte
+	for i := range buf {
+		buf[i] = r.next()
+	}
+	return int(binary.LittleEndian.Uint64(buf[:]))
+}
+
+func (r *byteReader) string() string {
+	n := int(r.next() % 8)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = r.next()
+	}
+	return string(b)
+}
+
+func (r *byteReader) inner() fzInner {
+	return fzInner{A: r.int(), B: r.string(), c: r.int()}
+}
+
+func (r *byteReader) inners() []fzInner {
+	if r.next()%4 == 0 {
+		return nil
+	}
+	l := make([]fzInner, r.next()%4)
+	for i := range l {
+		l[i] = r.inner()
+	}
+	return l
+}
+
+func (r *byteReader) shape() *fzShape {
+	s := &fzShape{I: r.int(), S: r.string(), F: float64(r.int()) / 7, B: r.next()%2 == 0}
+	if r.next()%2 == 0 {
+		in := r.inner()
+		s.P = &in
+	}
+	s.N = r.inner()
+	s.L = r.inners()
+	for _, in := range r.inners() {
+		in := in
+		if r.next()%3 == 0 {
+			s.LP = append(s.LP, nil)
+			continue
+		}
+		s.LP = append(s.LP, &in)
+	}
+	for i := range s.A {
+		s.A[i] = r.inner()
+	}
+	if r.next()%3 != 0 {
+		s.M = make(map[string]fzItem)
+		for _, in := range r.inners() {
+			s.M[r.string()] = fzItem{A: in.A, B: in.B}
+		}
+	}
+	for i := int(r.next() % 3); i > 0; i-- {
+		s.Tags = append(s.Tags, r.string())
+	}
+	s.u, s.us, s.ul, s.un = r.int(), r.string(), r.inners(), r.inner()
+	return s
+}
+
+func (r *byteReader) item() fzItem {
+	return fzItem{A: r.int(), B: r.string()}
+}
+
+func (r *byteReader) items() []fzItem {
+	if r.next()%4 == 0 {
+		return nil
+	}
+	l := make([]fzItem, r.next()%4)
+	for i := range l {
+		l[i] = r.item()
+	}
+	return l
+}
+
+// tree builds a tree of at most 3 levels.
+func (r *byteReader) tree(depth int) *fzTree {
+	t := &fzTree{V: r.int(), Name: r.string()}
+	if depth >= 2 {
+		return t
+	}
+	if r.next()%2 == 0 {
+		t.Next = r.tree(depth + 1)
+	}
+	for i := int(r.next() % 3); i > 0; i-- {
+		t.Kids = append(t.Kids, *r.tree(depth + 1))
+	}
+	if r.next()%2 == 0 {
+		t.Items = make(map[string]fzItem)
+		for i := int(r.next() % 3); i > 0; i-- {
+			t.Items[r.string()] = r.item()
+		}
+	}
+	return t
+}
+
+func (r *byteReader) nested() *fzNested {
+	n := &fzNested{fzItem: r.item(), U8: r.next(), I32: int32(r.int()), U: uint(r.int())}
+	if r.next()%2 == 0 {
+		opt := r.string()
+		n.Opt = &opt
+	}
+	if r.next()%2 == 0 {
+		in := r.inner()
+		in.c = 0 // unexported fields behind pointers to pointers are not compared
+		p := &in
+		n.PP = &p
+	}
+	if r.next()%2 == 0 {
+		n.Bytes = []byte(r.string())
+	}
+	for i := int(r.next() % 3); i > 0; i-- {
+		n.Matrix = append(n.Matrix, r.items())
+	}
+	if r.next()%2 == 0 {
+		n.Groups = make(map[string][]fzItem)
+		for i := int(r.next() % 3); i > 0; i-- {
+			n.Groups[r.string()] = r.items()
+		}
+	}
+	if r.next()%2 == 0 {
+		n.Indexed = make(map[int]map[string]fzItem)
+		for i := int(r.next() % 3); i > 0; i-- {
+			m := make(map[string]fzItem)
+			for _, item := range r.items() {
+				m[r.string()] = item
+			}
+			n.Indexed[int(r.next())] = m
+		}
+	}
+	return n
+}
+
+func FuzzMap(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("structsconv"))
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7, 8, 3, 'a', 'b', 'c', 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 'x', 'y', 3, 2, 1})
+
+	m := structsconv.New(structsconv.WithLogger(nil))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, c := range fuzzCases {
+			src := c.source(&byteReader{data: data})
+
+			for _, newTarget := range c.incompatible {
+				target := newTarget()
+				if err := fuzzMap(t, m, src, target); errors.Is(err, structsconv.ErrPanic) {
+					t.Fatalf("MapE(%T, %T) error = %v, want no panic", src, target, err)
+				}
+			}
+			for _, newTarget := range c.targets {
+				target := newTarget()
+				if err := fuzzMap(t, m, src, target); err != nil {
+					t.Fatalf("MapE(%T, %T) error = %v, want nil", src, target, err)
+				}
+				if !sameShapeEqual(reflect.ValueOf(src).Elem(), reflect.ValueOf(target).Elem()) {
+					t.Errorf("MapE(%T, %T) = %+v, want %+v", src, target, target, src)
+				}
+			}
+		}
+	})
+}
+
+// fuzzMap maps src to target, failing the test if MapE panics instead of returning the recovered panic.
+func fuzzMap(t *testing.T, m *structsconv.Mapper, src, target interface{}) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			t.Fatalf("MapE(%T, %T) panicked: %v", src, target, p)
+		}
+	}()
+	return m.MapE(src, target)
+}
+
+// sameShapeEqual compares two values of identical shape field by field, ignoring the type names.
+// Unexported fields are only compared when they can be mapped, see compareUnexported.
+// Nil and empty maps are considered equal, since the map of structs mapping always creates the target map.
+func sameShapeEqual(a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return sameShapeEqual(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !compareUnexported && !a.Type().Field(i).IsExported() {
+				continue
+			}
+			if !sameShapeEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameShapeEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, k := range a.MapKeys() {
+			if !b.MapIndex(k).IsValid() || !sameShapeEqual(a.MapIndex(k), b.MapIndex(k)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() == b.Uint()
+	case reflect.Float64:
+		return a.Float() == b.Float() || (a.Float() != a.Float() && b.Float() != b.Float())
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !structsconv_safe
+
+package structsconv_test
+
+// compareUnexported reports whether FuzzMap compares unexported fields, which are mapped by default.
+const compareUnexported = true
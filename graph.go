@@ -0,0 +1,183 @@
+package structsconv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GraphFormat is the output format of ExportGraph.
+type GraphFormat int
+
+const (
+	GraphDOT     GraphFormat = iota // Graphviz DOT
+	GraphMermaid                    // Mermaid flowchart
+)
+
+// graphNode is a struct type of the graph.
+type graphNode struct {
+	id    string // package path and name of the type, distinct for same-named types of different packages
+	label string // name of the type qualified by its package name
+}
+
+// newGraphNode returns the node of the struct type t.
+func newGraphNode(t reflect.Type) graphNode {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return graphNode{id: t.String(), label: t.String()}
+	}
+	return graphNode{id: t.PkgPath() + "." + t.Name(), label: t.String()}
+}
+
+// graphEdge is a mapping from a source struct type to a target struct type.
+type graphEdge struct {
+	source, target graphNode
+	rules          int      // rules of the pair, rename, function and ignored
+	registered     bool     // the pair has a registered RulesDefinition, otherwise it is reached through nested fields
+	unmapped       []string // target fields without mapping
+}
+
+// label returns the text of the edge, e.g. "2 rules, unmapped: Genre, Year".
+func (e graphEdge) label() string {
+	label := fmt.Sprintf("%d rules", e.rules)
+	if e.rules == 1 {
+		label = "1 rule"
+	}
+	if len(e.unmapped) > 0 {
+		label += ", unmapped: " + strings.Join(e.unmapped, ", ")
+	}
+	return label
+}
+
+// ExportGraph writes the graph of the registered mappings, including the pairs reached through nested fields.
+//
+// Nodes are struct types and edges go from source to target types, labeled with the number of rules and the
+// unmapped target fields. Pairs without a registered RulesDefinition are drawn with dashed edges.
+func ExportGraph(w io.Writer, format GraphFormat) error {
+	edges := collectGraphEdges()
+	switch format {
+	case GraphDOT:
+		return writeDOT(w, edges)
+	case GraphMermaid:
+		return writeMermaid(w, edges)
+	default:
+		return fmt.Errorf("rules error: unknown graph format %d", format)
+	}
+}
+
+// collectGraphEdges explains every registered pair and returns the edges of all the pairs found, sorted.
+func collectGraphEdges() []graphEdge {
+	registered := make(map[string]bool)
+	var keys []rulesKey
+	r := loadRegistry()
+	for k := range r.rules {
+		if k.source.Kind() != reflect.Struct || k.target.Kind() != reflect.Struct { // rules for ToMap and FromMap
+			continue
+		}
+		registered[newGraphNode(k.source).id+"->"+newGraphNode(k.target).id] = true
+		keys = append(keys, k)
+	}
+
+	found := make(map[string]graphEdge)
+	planKeys := make(map[*Plan]rulesKey)
+	var walk func(p *Plan)
+	walk = func(p *Plan) {
+		key := planKeys[p]
+		e := graphEdge{source: newGraphNode(key.source), target: newGraphNode(key.target)}
+		id := e.source.id + "->" + e.target.id
+		if _, ok := found[id]; ok || p.Recursive {
+			return
+		}
+		e.registered = registered[id]
+		for _, f := range p.Fields {
+			switch f.Strategy {
+			case StrategyRename, StrategyFunc, StrategySwitch, StrategyIgnored:
+				e.rules++
+			case StrategyUnmapped:
+				e.unmapped = append(e.unmapped, f.Target)
+			}
+		}
+		found[id] = e
+		for _, f := range p.Fields {
+			if f.Nested != nil {
+				walk(f.Nested)
+			}
+		}
+	}
+	for _, k := range keys {
+		walk(explainStructs(r, k.source, k.target, make(map[rulesKey]bool), planKeys))
+	}
+
+	edges := make([]graphEdge, 0, len(found))
+	for _, e := range found {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.source != b.source {
+			if a.source.label != b.source.label {
+				return a.source.label < b.source.label
+			}
+			return a.source.id < b.source.id
+		}
+		if a.target.label != b.target.label {
+			return a.target.label < b.target.label
+		}
+		return a.target.id < b.target.id
+	})
+	return edges
+}
+
+func writeDOT(w io.Writer, edges []graphEdge) error {
+	var b strings.Builder
+	b.WriteString("digraph structsconv {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	declared := make(map[string]bool)
+	for _, e := range edges {
+		for _, n := range []graphNode{e.source, e.target} {
+			if !declared[n.id] {
+				declared[n.id] = true
+				fmt.Fprintf(&b, "\t%s [label=%s];\n", strconv.Quote(n.id), strconv.Quote(n.label))
+			}
+		}
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s", strconv.Quote(e.source.id), strconv.Quote(e.target.id), strconv.Quote(e.label()))
+		if !e.registered {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMermaid(w io.Writer, edges []graphEdge) error {
+	// node ids in order of appearance, Mermaid ids can not contain the type names characters
+	ids := make(map[string]string)
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	node := func(n graphNode) string {
+		if id, ok := ids[n.id]; ok {
+			return id
+		}
+		ids[n.id] = "n" + strconv.Itoa(len(ids))
+		return ids[n.id] + "[\"" + mermaidEscape(n.label) + "\"]"
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if !e.registered {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s|\"%s\"| %s\n", node(e.source), arrow, mermaidEscape(e.label()), node(e.target))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape escapes the double quotes of a Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,94 @@
+package structsconv
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+type graphItemSource struct{ Title string }
+type graphItemTarget struct {
+	Title string
+	Genre string
+}
+type graphSource struct {
+	UserID int
+	Items  []graphItemSource
+}
+type graphTarget struct {
+	ID    int
+	Items []graphItemTarget
+}
+
+func registerGraphRules(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: graphSource{},
+		Target: graphTarget{},
+		Rules:  RulesSet{"ID": "UserID"},
+	})
+}
+
+func Test_ExportGraph_DOT(t *testing.T) {
+	registerGraphRules(t)
+
+	var buf bytes.Buffer
+	if err := ExportGraph(&buf, GraphDOT); err != nil {
+		t.Fatalf("ExportGraph() error = %v, want nil", err)
+	}
+	want := "digraph structsconv {\n" +
+		"\trankdir=LR;\n" +
+		"\tnode [shape=box];\n" +
+		"\t\"github.com/rendis/structsconv.graphItemSource\" [label=\"structsconv.graphItemSource\"];\n" +
+		"\t\"github.com/rendis/structsconv.graphItemTarget\" [label=\"structsconv.graphItemTarget\"];\n" +
+		"\t\"github.com/rendis/structsconv.graphSource\" [label=\"structsconv.graphSource\"];\n" +
+		"\t\"github.com/rendis/structsconv.graphTarget\" [label=\"structsconv.graphTarget\"];\n" +
+		"\t\"github.com/rendis/structsconv.graphItemSource\" -> \"github.com/rendis/structsconv.graphItemTarget\" [label=\"0 rules, unmapped: Genre\", style=dashed];\n" +
+		"\t\"github.com/rendis/structsconv.graphSource\" -> \"github.com/rendis/structsconv.graphTarget\" [label=\"1 rule\"];\n" +
+		"}\n"
+	if buf.String() != want {
+		t.Errorf("ExportGraph() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_ExportGraph_Mermaid(t *testing.T) {
+	registerGraphRules(t)
+
+	var buf bytes.Buffer
+	if err := ExportGraph(&buf, GraphMermaid); err != nil {
+		t.Fatalf("ExportGraph() error = %v, want nil", err)
+	}
+	want := "flowchart LR\n" +
+		"\tn0[\"structsconv.graphItemSource\"] -.->|\"0 rules, unmapped: Genre\"| n1[\"structsconv.graphItemTarget\"]\n" +
+		"\tn2[\"structsconv.graphSource\"] -->|\"1 rule\"| n3[\"structsconv.graphTarget\"]\n"
+	if buf.String() != want {
+		t.Errorf("ExportGraph() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_ExportGraph_sameNamedTypes(t *testing.T) {
+	type source struct{ T template.Template }
+	type target struct{ T htmltemplate.Template }
+	withRegistry(t, RulesDefinition{Source: source{}, Target: target{}})
+
+	var buf bytes.Buffer
+	if err := ExportGraph(&buf, GraphDOT); err != nil {
+		t.Fatalf("ExportGraph() error = %v, want nil", err)
+	}
+	for _, want := range []string{
+		"\t\"text/template.Template\" [label=\"template.Template\"];\n",
+		"\t\"html/template.Template\" [label=\"template.Template\"];\n",
+		"\t\"text/template.Template\" -> \"html/template.Template\" [label=",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ExportGraph() = %q, want to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func Test_ExportGraph_unknown_format(t *testing.T) {
+	if err := ExportGraph(&bytes.Buffer{}, GraphFormat(9)); err == nil {
+		t.Errorf("ExportGraph() error = nil, want unknown format error")
+	}
+}
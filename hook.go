@@ -0,0 +1,131 @@
+package structsconv
+
+import (
+	"log"
+	"reflect"
+	"sync"
+)
+
+// afterMapper is implemented by the targets that complete themselves once mapped, e.g. to normalize their fields.
+type afterMapper interface {
+	AfterMap()
+}
+
+// validator is implemented by the targets that validate themselves once mapped.
+type validator interface {
+	Validate() error
+}
+
+// targetMethods caches, by target type, which of afterMapper and validator its pointer implements.
+var targetMethods sync.Map
+
+// targetMethodsOf reports whether the pointer to the target type t implements afterMapper and validator.
+func targetMethodsOf(t reflect.Type) (afterMap, validate bool) {
+	if m, ok := targetMethods.Load(t); ok {
+		flags := m.([2]bool)
+		return flags[0], flags[1]
+	}
+	ptr := reflect.PointerTo(t)
+	flags := [2]bool{
+		ptr.Implements(reflect.TypeOf((*afterMapper)(nil)).Elem()),
+		ptr.Implements(reflect.TypeOf((*validator)(nil)).Elem()),
+	}
+	targetMethods.Store(t, flags)
+	return flags[0], flags[1]
+}
+
+// checkHook checks that the hook is a function returning nothing or an error, the name is BeforeMap or AfterMap.
+// Returns an invalid value if there is no hook.
+func checkHook(key rulesKey, name string, hook interface{}) reflect.Value {
+	if hook == nil {
+		return reflect.Value{}
+	}
+	v := reflect.ValueOf(hook)
+	if v.Kind() != reflect.Func {
+		log.Panicf(
+			"ERROR: (%s -> %s) %s must be a function. %s = %v.\n",
+			key.source.String(), key.target.String(), name, name, hook,
+		)
+	}
+	checkFuncParams(v.Type(), name, key)
+	if v.Type().NumOut() > 1 || (v.Type().NumOut() == 1 && v.Type().Out(0) != errorType) {
+		log.Panicf(
+			"ERROR: (%s -> %s) Function '%s' must return nothing or an error. Function = '%s'.\n",
+			key.source.String(), key.target.String(), name, v.Type().String(),
+		)
+	}
+	return v
+}
+
+// beforeMap calls the BeforeMap hook of the definition, if any, before mapping the fields of the target.
+func (s *rulesStrategy) beforeMap(target, actualS reflect.Value, state *mappingState) error {
+	if s == nil || !s.beforeMapHook.IsValid() {
+		return nil
+	}
+	ptr, ok := targetPointer(target, state)
+	if !ok {
+		return nil
+	}
+	return callHook(s.beforeMapHook, ptr, actualS, state)
+}
+
+// afterMap completes the mapped target by calling, in order:
+//   - the AfterMap hook of the definition, if any
+//   - the AfterMap() method of the target, if it implements one
+//   - the Validate() error method of the target, if it implements one
+//
+// The methods of the target are not called by Clone, which copies values as they are.
+func (s *rulesStrategy) afterMap(target, actualS reflect.Value, state *mappingState) error {
+	hasHook := s != nil && s.afterMapHook.IsValid()
+	var afterMap, validate bool
+	if !state.cloning {
+		afterMap, validate = targetMethodsOf(target.Type())
+	}
+	if !hasHook && !afterMap && !validate {
+		return nil
+	}
+	ptr, ok := targetPointer(target, state)
+	if !ok {
+		return nil
+	}
+	if hasHook {
+		if err := callHook(s.afterMapHook, ptr, actualS, state); err != nil {
+			return err
+		}
+	}
+	if afterMap {
+		ptr.Interface().(afterMapper).AfterMap()
+	}
+	if validate {
+		return ptr.Interface().(validator).Validate()
+	}
+	return nil
+}
+
+// targetPointer returns the pointer to the target, false if it cannot be used by the hooks.
+func targetPointer(target reflect.Value, state *mappingState) (reflect.Value, bool) {
+	if !target.CanAddr() {
+		return reflect.Value{}, false
+	}
+	if !target.CanInterface() {
+		if !state.unexportedAllowed() {
+			return reflect.Value{}, false
+		}
+		target = getUnexportedField(target)
+	}
+	return target.Addr(), true
+}
+
+// callHook calls a hook with its parameters, ptr is the pointer to the target being mapped.
+func callHook(hook, ptr, actualS reflect.Value, state *mappingState) error {
+	params, err := getMethodParams(hook.Type(), state, actualS, ptr)
+	if err != nil {
+		return err
+	}
+	results := hook.Call(params)
+	clear(params) // the buffer is reused, do not retain the arguments
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
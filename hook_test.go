@@ -0,0 +1,125 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type memberDto struct {
+	Name  string
+	Email string
+}
+
+type member struct {
+	Name  string
+	Email string
+	Trace []string
+}
+
+func (m *member) AfterMap() { m.Email = strings.ToLower(m.Email) }
+
+func (m *member) Validate() error {
+	if m.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
+
+type teamDto struct {
+	Lead    memberDto
+	Members []memberDto
+}
+
+type team struct {
+	Lead    member
+	Members []member
+}
+
+type hookTrace string
+
+func Test_Map_hooks(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: memberDto{},
+		Target: member{},
+		BeforeMap: func(s memberDto, m *member, trace hookTrace) {
+			m.Trace = append(m.Trace, string(trace)+" before "+s.Name)
+		},
+		AfterMap: func(m *member, i Index) error {
+			m.Trace = append(m.Trace, "after "+m.Email)
+			if m.Name == "" {
+				return errors.New("name is required")
+			}
+			return nil
+		},
+	})
+
+	src := teamDto{
+		Lead:    memberDto{Name: "ann", Email: "ANN@X.ORG"},
+		Members: []memberDto{{Name: "bob", Email: "BOB@X.ORG"}},
+	}
+	var got team
+	if err := New(WithLogger(nil)).MapE(&src, &got, hookTrace(">")); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	want := team{
+		Lead:    member{Name: "ann", Email: "ann@x.org", Trace: []string{"> before ann", "after ANN@X.ORG"}},
+		Members: []member{{Name: "bob", Email: "bob@x.org", Trace: []string{"> before bob", "after BOB@X.ORG"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+
+	src.Members = append(src.Members, memberDto{Email: "C@X.ORG"})
+	var me *MappingError
+	if err := New(WithLogger(nil)).MapE(&src, &got, hookTrace(">")); !errors.As(err, &me) || me.Path != "Members[1]" {
+		t.Errorf("MapE() error = %v, want path Members[1]", err)
+	}
+}
+
+func Test_Map_validate(t *testing.T) {
+	withRegistry(t)
+	src := teamDto{
+		Lead:    memberDto{Name: "ann", Email: "ann@x.org"},
+		Members: []memberDto{{Name: "bob", Email: "bob@x.org"}, {Name: "carl"}},
+	}
+	var got team
+	err := New(WithLogger(nil)).MapE(&src, &got)
+	var me *MappingError
+	if !errors.As(err, &me) || me.Path != "Members[1]" || me.Err.Error() != "email is required" {
+		t.Errorf("MapE() error = %v, want email is required at Members[1]", err)
+	}
+
+	var root member
+	if err := New(WithLogger(nil)).MapE(&src.Members[1], &root); err == nil || err.Error() != "email is required" {
+		t.Errorf("MapE() error = %v, want email is required", err)
+	}
+}
+
+func Test_registerRules_hook_panics(t *testing.T) {
+	tests := []struct {
+		name         string
+		definition   RulesDefinition
+		wantContains string
+	}{
+		{
+			name:         "BeforeMap is not a function,panic expected",
+			definition:   RulesDefinition{Source: memberDto{}, Target: member{}, BeforeMap: "before"},
+			wantContains: "BeforeMap must be a function",
+		},
+		{
+			name:         "AfterMap returns a value,panic expected",
+			definition:   RulesDefinition{Source: memberDto{}, Target: member{}, AfterMap: func(*member) bool { return true }},
+			wantContains: "Function 'AfterMap' must return nothing or an error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRegistry(t)
+			var f = func() { RegisterRulesDefinitions(tt.definition) }
+			assertPanic(f, tt.wantContains, t)
+		})
+	}
+}
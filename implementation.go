@@ -0,0 +1,98 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// ErrImplementationNotFound is returned when a value in an interface field has no registered implementation
+// for the target interface and can't be assigned to it.
+var ErrImplementationNotFound = errors.New("implementation not found")
+
+// RegisterImplementation registers that a value of the source concrete type, in a field mapped to a target field
+// of the given interface, is mapped to a value of the target concrete type.
+//
+// The interface is given as a nil pointer to it, and the target type must implement it:
+//
+//	structsconv.RegisterImplementation((*domain.PaymentMethod)(nil), dto.CardDto{}, domain.Card{})
+//	structsconv.RegisterImplementation((*domain.PaymentMethod)(nil), dto.PixDto{}, &domain.Pix{})
+//
+// The source value is mapped to the target type as any other field, with the rules registered for the pair.
+func RegisterImplementation(iface, source, target interface{}) {
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+		log.Panicf("ERROR: %v is not a pointer to an interface, like (*MyInterface)(nil).", ifaceType)
+	}
+	ifaceType = ifaceType.Elem()
+
+	sourceType := reflect.TypeOf(source)
+	targetType := reflect.TypeOf(target)
+	if sourceType == nil || targetType == nil {
+		log.Panicf("ERROR: Implementation of %s must have a source and a target value.", ifaceType)
+	}
+	if !targetType.Implements(ifaceType) {
+		log.Panicf("ERROR: %s does not implement %s.", targetType, ifaceType)
+	}
+
+	updateRegistry(func(r *registry) {
+		impls, exists := r.implementations[ifaceType]
+		if !exists {
+			impls = make(map[reflect.Type]reflect.Type)
+			r.implementations[ifaceType] = impls
+		}
+		if _, exists := impls[sourceType]; exists {
+			log.Panicf("ERROR: Implementation of %s for %s already exists.", ifaceType, sourceType)
+		}
+		impls[sourceType] = targetType
+	})
+}
+
+// isImplementationMapping reports whether a source of the given type is mapped to the target interface type
+// using the implementations of the registry r.
+func isImplementationMapping(r *registry, source, target reflect.Type) bool {
+	if target.Kind() != reflect.Interface {
+		return false
+	}
+	impls := r.implementations[target]
+	if source.Kind() == reflect.Interface {
+		return len(impls) > 0
+	}
+	_, exists := impls[source]
+	return exists
+}
+
+// cMappingInterfaceLogic maps the concrete source value to the implementation registered for the target interface.
+//
+// A nil source is not mapped, and a source without implementation is assigned if possible.
+func cMappingInterfaceLogic(sourceValue, targetValue reflect.Value, state *mappingState) error {
+	if !sourceValue.CanInterface() && sourceValue.CanAddr() {
+		sourceValue = getUnexportedField(sourceValue)
+	}
+	if sourceValue.Kind() == reflect.Interface {
+		if sourceValue.IsNil() {
+			return nil
+		}
+		// the concrete value is not addressable, copy it so its unexported fields can be read
+		concrete := reflect.New(sourceValue.Elem().Type()).Elem()
+		concrete.Set(sourceValue.Elem())
+		sourceValue = concrete
+	}
+
+	implType, exists := state.registry.implementations[targetValue.Type()][sourceValue.Type()]
+	if !exists {
+		if sourceValue.Type().AssignableTo(targetValue.Type()) {
+			mappingDirectMapping(sourceValue, targetValue, state)
+			return nil
+		}
+		return fmt.Errorf("%w: %s for %s", ErrImplementationNotFound, sourceValue.Type(), targetValue.Type())
+	}
+
+	item := reflect.New(implType).Elem()
+	if _, err := fieldToField(sourceValue, item, state); err != nil {
+		return err
+	}
+	mappingDirectMapping(item, targetValue, state)
+	return nil
+}
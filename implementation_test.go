@@ -0,0 +1,120 @@
+package structsconv
+
+import (
+	"errors"
+	"testing"
+)
+
+type paymentMethodDto interface{ isPaymentMethodDto() }
+
+type cardDto struct {
+	Number string
+	holder string
+}
+
+func (cardDto) isPaymentMethodDto() {}
+
+type pixDto struct{ Key string }
+
+func (*pixDto) isPaymentMethodDto() {}
+
+type boletoDto struct{ Code string }
+
+func (boletoDto) isPaymentMethodDto() {}
+
+type paymentMethod interface{ Kind() string }
+
+type card struct {
+	Last4  string
+	holder string
+}
+
+func (card) Kind() string { return "card" }
+
+type pix struct{ Key string }
+
+func (*pix) Kind() string { return "pix" }
+
+type cash struct{}
+
+func (cash) Kind() string { return "cash" }
+
+type orderDto struct {
+	Payment  paymentMethodDto
+	Card     cardDto
+	Payments []paymentMethodDto
+	payment  paymentMethodDto
+	Other    paymentMethod
+}
+
+type order struct {
+	Payment  paymentMethod
+	Card     paymentMethod
+	Payments []paymentMethod
+	payment  paymentMethod
+	Other    paymentMethod
+}
+
+func init() {
+	RegisterImplementation((*paymentMethod)(nil), cardDto{}, card{})
+	RegisterImplementation((*paymentMethod)(nil), &pixDto{}, &pix{})
+	RegisterRulesDefinitions(RulesDefinition{
+		Source: cardDto{},
+		Target: card{},
+		Rules: RulesSet{
+			"Last4": func(c cardDto) string { return c.Number[len(c.Number)-4:] },
+		},
+	})
+}
+
+func Test_Map_implementations_notFound(t *testing.T) {
+	src := orderDto{Payment: boletoDto{Code: "123"}}
+	var got order
+	err := MapE(&src, &got)
+	if !errors.Is(err, ErrImplementationNotFound) {
+		t.Fatalf("MapE() error = %v, want %v", err, ErrImplementationNotFound)
+	}
+	var mErr *MappingError
+	if !errors.As(err, &mErr) || mErr.Path != "Payment" {
+		t.Errorf("MapE() error = %v, want path 'Payment'", err)
+	}
+}
+
+func Test_RegisterImplementation_panics(t *testing.T) {
+	tests := []struct {
+		name         string
+		iface        interface{}
+		source       interface{}
+		target       interface{}
+		wantContains string
+	}{
+		{
+			name:         "Interface is not a pointer to an interface,panic expected",
+			iface:        card{},
+			source:       cardDto{},
+			target:       card{},
+			wantContains: "structsconv.card is not a pointer to an interface",
+		},
+		{
+			name:         "Target does not implement the interface,panic expected",
+			iface:        (*paymentMethod)(nil),
+			source:       pixDto{},
+			target:       pix{},
+			wantContains: "structsconv.pix does not implement structsconv.paymentMethod",
+		},
+		{
+			name:         "Implementation already exists,panic expected",
+			iface:        (*paymentMethod)(nil),
+			source:       cardDto{},
+			target:       card{},
+			wantContains: "Implementation of structsconv.paymentMethod for structsconv.cardDto already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f = func() { RegisterImplementation(tt.iface, tt.source, tt.target) }
+			assertPanic(f, tt.wantContains, t)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !structsconv_safe
+
+package structsconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Map_implementations(t *testing.T) {
+	src := orderDto{
+		Payment:  cardDto{Number: "4111111111111111", holder: "john"},
+		Card:     cardDto{Number: "5500000000000004"},
+		Payments: []paymentMethodDto{&pixDto{Key: "k1"}, nil, cardDto{Number: "1234"}},
+		payment:  &pixDto{Key: "k2"},
+		Other:    cash{},
+	}
+	want := order{
+		Payment:  card{Last4: "1111", holder: "john"},
+		Card:     card{Last4: "0004"},
+		Payments: []paymentMethod{&pix{Key: "k1"}, nil, card{Last4: "1234"}},
+		payment:  &pix{Key: "k2"},
+		Other:    cash{},
+	}
+
+	var got order
+	if err := MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+}
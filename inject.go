@@ -0,0 +1,311 @@
+package structsconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// getMethodParams gets the arguments of the method based on its input parameters.
+//
+// Each parameter is resolved in the following order:
+//   - the current source struct, by exact type (only the first parameter of that type)
+//   - the target struct being mapped, for parameters of its pointer type (hooks only, target is invalid for rules)
+//   - the mapping context, for parameters of type context.Context
+//   - the position of the current source struct, for parameters of type Parent, Index, MapKey and Path
+//   - the arguments passed to Map, by exact type, consumed in the order they were passed
+//   - the named arguments, for parameters of type Args or structs with fields tagged with `structsconv:"name"`
+//   - for interface parameters, the arguments passed to Map whose type implements the interface
+//     (methods promoted from embedded fields included), see getAssignableArgType
+//   - the zero value of the parameter type
+//
+// The steps that only depend on the types are resolved once per function, see getParamLayout.
+func getMethodParams(method reflect.Type, state *mappingState, current, target reflect.Value) ([]reflect.Value, error) {
+	var targetType reflect.Type
+	if target.IsValid() {
+		targetType = target.Type()
+	}
+	layout := getParamLayout(method, current.Type(), targetType)
+	if cap(state.params) < len(layout) {
+		state.params = make([]reflect.Value, len(layout))
+	}
+	params := state.params[:len(layout)]
+	// consumed arguments of each group of state.args, on the stack for the usual number of argument types
+	var buf [8]int
+	argsCounter := buf[:]
+	if len(state.args) > len(buf) {
+		argsCounter = make([]int, len(state.args))
+	}
+	for i, p := range layout {
+		switch p.kind {
+		case paramCurrent:
+			params[i] = current
+			continue
+		case paramTarget:
+			params[i] = target
+			continue
+		case paramContext:
+			params[i] = reflect.ValueOf(state.ctx)
+			continue
+		case paramParent:
+			params[i] = reflect.ValueOf(state.parent())
+			continue
+		case paramIndex:
+			params[i] = reflect.ValueOf(state.index())
+			continue
+		case paramMapKey:
+			params[i] = reflect.ValueOf(state.mapKey())
+			continue
+		case paramPath:
+			params[i] = reflect.ValueOf(state.path())
+			continue
+		}
+
+		// Arguments
+		if g := state.args.find(p.t); g >= 0 && argsCounter[g] < len(state.args[g].values) {
+			params[i] = state.args[g].values[argsCounter[g]]
+			argsCounter[g]++
+			continue
+		}
+
+		switch p.kind {
+		// Named arguments
+		case paramArgOrArgs:
+			params[i] = reflect.ValueOf(Args{named: state.named})
+			continue
+		case paramArgOrNamedStruct:
+			v, err := buildNamedArgsStruct(p.t, state.named)
+			if err != nil {
+				return nil, fmt.Errorf("function (%s), argument %d: %w", method, i+1, err)
+			}
+			params[i] = v
+			continue
+
+		// Arguments assignable to an interface parameter
+		case paramArgOrInterface:
+			aType, err := getAssignableArgType(p.t, state.argTypes)
+			if err != nil {
+				return nil, fmt.Errorf("function (%s), argument %d: %w", method, i+1, err)
+			}
+			if g := state.args.find(aType); aType != nil && g >= 0 && argsCounter[g] < len(state.args[g].values) {
+				params[i] = state.args[g].values[argsCounter[g]]
+				argsCounter[g]++
+				continue
+			}
+		}
+
+		// Zero value
+		logPassingZeroValue(state, method, p.t, i+1)
+		params[i] = reflect.Zero(p.t)
+	}
+	return params, nil
+}
+
+// paramKind is how a parameter of a rule function or hook is resolved, see getMethodParams.
+type paramKind uint8
+
+const (
+	paramCurrent          paramKind = iota // the current source struct
+	paramTarget                            // the target struct being mapped
+	paramContext                           // the mapping context
+	paramParent                            // the Parent position marker
+	paramIndex                             // the Index position marker
+	paramMapKey                            // the MapKey position marker
+	paramPath                              // the Path position marker
+	paramArg                               // an argument passed to Map, or the zero value
+	paramArgOrArgs                         // an argument passed to Map, or the named arguments
+	paramArgOrNamedStruct                  // an argument passed to Map, or a struct built from the named arguments
+	paramArgOrInterface                    // an argument passed to Map, or the one implementing the interface, or the zero value
+)
+
+// paramLayout is the resolution of a parameter that only depends on its type.
+type paramLayout struct {
+	kind paramKind
+	t    reflect.Type
+}
+
+// paramLayoutKey identifies a function called with a current source type, and a target type for the hooks.
+type paramLayoutKey struct {
+	method, current, target reflect.Type
+}
+
+// paramLayouts caches the layouts of the parameters by paramLayoutKey, they are resolved on every rule call.
+var paramLayouts sync.Map
+
+// getParamLayout returns how every parameter of the method is resolved, target is nil for the rule functions.
+func getParamLayout(method, current, target reflect.Type) []paramLayout {
+	key := paramLayoutKey{method, current, target}
+	if l, ok := paramLayouts.Load(key); ok {
+		return l.([]paramLayout)
+	}
+	layout := make([]paramLayout, method.NumIn())
+	var cFlag bool
+	for i := range layout {
+		t := method.In(i)
+		layout[i].t = t
+		switch {
+		case !cFlag && t == current:
+			layout[i].kind = paramCurrent
+			cFlag = true
+		case target != nil && t == target:
+			layout[i].kind = paramTarget
+		case t == contextType:
+			layout[i].kind = paramContext
+		case t == parentType:
+			layout[i].kind = paramParent
+		case t == indexType:
+			layout[i].kind = paramIndex
+		case t == mapKeyType:
+			layout[i].kind = paramMapKey
+		case t == pathType:
+			layout[i].kind = paramPath
+		case t == argsType:
+			layout[i].kind = paramArgOrArgs
+		case isNamedArgsStruct(t):
+			layout[i].kind = paramArgOrNamedStruct
+		case t.Kind() == reflect.Interface:
+			layout[i].kind = paramArgOrInterface
+		default:
+			layout[i].kind = paramArg
+		}
+	}
+	paramLayouts.Store(key, layout)
+	return layout
+}
+
+// getAssignableArgType returns the only argument type assignable to the interface type iType.
+//
+// The root source is not a candidate, only the arguments passed to Map. Returns nil if no argument type
+// implements the interface, and an error if more than one distinct argument type implements it.
+// Arguments of the same type are not ambiguous, they are consumed in the order they were passed.
+func getAssignableArgType(iType reflect.Type, argTypes []reflect.Type) (reflect.Type, error) {
+	var found reflect.Type
+	for _, t := range argTypes {
+		if !t.AssignableTo(iType) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("ambiguous argument for interface '%s', both '%s' and '%s' implement it", iType, found, t)
+		}
+		found = t
+	}
+	return found, nil
+}
+
+// getArgTypes returns the distinct types of the arguments, in the order they were passed.
+func getArgTypes(args []interface{}) []reflect.Type {
+	var types []reflect.Type
+	seen := make(map[reflect.Type]bool)
+	for _, v := range args {
+		t := reflect.TypeOf(v)
+		if t == nil || seen[t] {
+			continue
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types
+}
+
+// Parent can be requested by a rule function to get the source struct that contains the current source struct.
+//
+// Value is nil for the rules of the root struct. Items of slices, arrays and maps are contained by the struct
+// that holds the collection, e.g. the parent of UserInfo.Addresses[i] is the UserInfo source struct.
+type Parent struct {
+	Value interface{}
+}
+
+// Index can be requested by a rule function to get the index of the nearest slice or array item that contains
+// the current source struct, -1 if there is none.
+type Index int
+
+// MapKey can be requested by a rule function to get the key of the nearest map item that contains
+// the current source struct. Value is nil if there is none.
+type MapKey struct {
+	Value interface{}
+}
+
+// Path can be requested by a rule function to get the path of the target field being mapped, e.g. "Info.Addresses[0].Zip".
+type Path string
+
+var (
+	parentType = reflect.TypeOf(Parent{})
+	indexType  = reflect.TypeOf(Index(0))
+	mapKeyType = reflect.TypeOf(MapKey{})
+	pathType   = reflect.TypeOf(Path(""))
+)
+
+// pushField adds a target field frame, owner is the source struct being mapped.
+func (s *mappingState) pushField(field string, owner reflect.Value) {
+	s.frames = append(s.frames, mappingFrame{field: field, owner: owner, index: -1})
+}
+
+// pushIndex adds a slice or array item frame.
+func (s *mappingState) pushIndex(i int) {
+	s.frames = append(s.frames, mappingFrame{index: i})
+}
+
+// pushKey adds a map item frame.
+func (s *mappingState) pushKey(key reflect.Value) {
+	s.frames = append(s.frames, mappingFrame{index: -1, key: key})
+}
+
+// pop removes the last frame.
+func (s *mappingState) pop() {
+	s.frames = s.frames[:len(s.frames)-1]
+}
+
+// parent returns the owner of the field frame that precedes the current field frame.
+func (s *mappingState) parent() Parent {
+	for i := len(s.frames) - 2; i >= 0; i-- {
+		if s.frames[i].field != "" {
+			return Parent{Value: s.frames[i].owner.Interface()}
+		}
+	}
+	return Parent{}
+}
+
+// index returns the index of the nearest slice or array item frame.
+func (s *mappingState) index() Index {
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		if s.frames[i].field == "" && !s.frames[i].key.IsValid() {
+			return Index(s.frames[i].index)
+		}
+	}
+	return -1
+}
+
+// mapKey returns the key of the nearest map item frame.
+func (s *mappingState) mapKey() MapKey {
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		if s.frames[i].key.IsValid() {
+			return MapKey{Value: s.frames[i].key.Interface()}
+		}
+	}
+	return MapKey{}
+}
+
+// path returns the path of the current frame from the root.
+func (s *mappingState) path() Path {
+	return pathOf(s.frames)
+}
+
+// pathOf returns the path of the given frames.
+func pathOf(frames []mappingFrame) Path {
+	var b strings.Builder
+	for _, f := range frames {
+		switch {
+		case f.field != "":
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(f.field)
+		case f.key.IsValid():
+			fmt.Fprintf(&b, "[%v]", f.key)
+		default:
+			fmt.Fprintf(&b, "[%d]", f.index)
+		}
+	}
+	return Path(b.String())
+}
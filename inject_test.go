@@ -0,0 +1,178 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testClock struct{ now string }
+
+func (c testClock) String() string { return c.now }
+
+type testLabel string
+
+func (l testLabel) String() string { return string(l) }
+
+type testAuditedClock struct {
+	testClock
+	User string
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func Test_getAssignableArgType(t *testing.T) {
+	tests := []struct {
+		name     string
+		argTypes []reflect.Type
+		want     reflect.Type
+		wantErr  string
+	}{
+		{
+			name:     "getAssignableArgType,no candidates",
+			argTypes: []reflect.Type{reflect.TypeOf(1), reflect.TypeOf("")},
+			want:     nil,
+		},
+		{
+			name:     "getAssignableArgType,one candidate",
+			argTypes: []reflect.Type{reflect.TypeOf(1), reflect.TypeOf(testClock{})},
+			want:     reflect.TypeOf(testClock{}),
+		},
+		{
+			name:     "getAssignableArgType,promoted method",
+			argTypes: []reflect.Type{reflect.TypeOf(testAuditedClock{})},
+			want:     reflect.TypeOf(testAuditedClock{}),
+		},
+		{
+			name:     "getAssignableArgType,ambiguous",
+			argTypes: []reflect.Type{reflect.TypeOf(testClock{}), reflect.TypeOf(testLabel(""))},
+			wantErr:  "ambiguous argument for interface 'fmt.Stringer'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getAssignableArgType(stringerType, tt.argTypes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("getAssignableArgType() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("getAssignableArgType() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Map_interface_param(t *testing.T) {
+	type source struct{ Name string }
+	type target struct {
+		Name    string
+		Created string
+		Label   string
+	}
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules: RulesSet{
+			"Created": func(s fmt.Stringer) string { return s.String() },
+			"Label":   func(c testClock, s fmt.Stringer) string { return c.now + "," + s.String() },
+		},
+	})
+
+	d := &target{}
+	if err := MapE(&source{Name: "name"}, d, testClock{now: "t1"}, testClock{now: "t2"}); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+	want := &target{Name: "name", Created: "t1", Label: "t1,t2"}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("MapE() = %v, want %v", d, want)
+	}
+
+	err := MapE(&source{Name: "name"}, &target{}, testClock{now: "t1"}, testLabel("label"))
+	var me *MappingError
+	if !errors.As(err, &me) || !strings.Contains(err.Error(), "ambiguous argument for interface 'fmt.Stringer'") {
+		t.Errorf("MapE() error = %v, want ambiguous argument error", err)
+	}
+}
+
+func Test_Map_position_params(t *testing.T) {
+	type bookSource struct{ Title string }
+	type bookTarget struct {
+		Title string
+		Ref   string
+	}
+	type addressSource struct{ Street string }
+	type addressTarget struct {
+		Street string
+		Ref    string
+	}
+	type source struct {
+		Name      string
+		Addresses []addressSource
+		Books     map[string]bookSource
+	}
+	type target struct {
+		Name      string
+		Ref       string
+		Addresses []addressTarget
+		Books     map[string]bookTarget
+	}
+
+	withRegistry(t,
+		RulesDefinition{
+			Source: source{},
+			Target: target{},
+			Rules: RulesSet{
+				"Ref": func(p Parent, i Index, k MapKey, path Path) string {
+					return fmt.Sprintf("%v|%d|%v|%s", p.Value, i, k.Value, path)
+				},
+			},
+		},
+		RulesDefinition{
+			Source: addressSource{},
+			Target: addressTarget{},
+			Rules: RulesSet{
+				"Ref": func(p Parent, i Index, path Path) string {
+					return fmt.Sprintf("%s|%d|%s", p.Value.(source).Name, i, path)
+				},
+			},
+		},
+		RulesDefinition{
+			Source: bookSource{},
+			Target: bookTarget{},
+			Rules: RulesSet{
+				"Ref": func(k MapKey, i Index, path Path) string {
+					return fmt.Sprintf("%v|%d|%s", k.Value, i, path)
+				},
+			},
+		},
+	)
+
+	o := &source{
+		Name:      "name",
+		Addresses: []addressSource{{Street: "s1"}, {Street: "s2"}},
+		Books:     map[string]bookSource{"sci-fi": {Title: "t1"}},
+	}
+	d := &target{}
+	if err := MapE(o, d); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+
+	want := &target{
+		Name: "name",
+		Ref:  "<nil>|-1|<nil>|Ref",
+		Addresses: []addressTarget{
+			{Street: "s1", Ref: "name|0|Addresses[0].Ref"},
+			{Street: "s2", Ref: "name|1|Addresses[1].Ref"},
+		},
+		Books: map[string]bookTarget{"sci-fi": {Title: "t1", Ref: "sci-fi|-1|Books[sci-fi].Ref"}},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("MapE() = %+v, want %+v", d, want)
+	}
+}
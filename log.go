@@ -1,47 +1,184 @@
 package structsconv
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"reflect"
-	"sync"
+	"strings"
 )
 
-var (
-	logWarning = true
-	setLogOnce sync.Once
-)
+// Logger receives the log messages of a Mapper, args are key-value pairs as in log/slog.
+//
+// Levels are used as follows:
+//   - Debug: fields mapped by name and rules checking
+//   - Info: fields marked as ignored
+//   - Warn: incompatible types, missing source fields and zero values passed to rules
+//
+// *slog.Logger implements Logger. Loggers with an Enabled(context.Context, slog.Level) bool method, like *slog.Logger,
+// are only called for the enabled levels, avoiding to build the messages of the disabled ones.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// NewSlogLogger returns a Logger that writes to l, or to slog.Default() if l is nil.
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
 
-// SetLogWarning turns on (true) or off (false) the warning log messages. Default is on (true).
+// NewStdLogger returns a Logger that writes to the standard log package the messages of level or higher.
 //
-// If you want to turn off the warning log messages, you can call this function before you call any other functions.
+// Messages are prefixed with the level, e.g. "WARNING: ...", and followed by their attributes.
+func NewStdLogger(level slog.Level) Logger {
+	return stdLogger{level: level}
+}
+
+// stdLogger writes to the standard log package.
+type stdLogger struct {
+	level slog.Level
+}
+
+func (l stdLogger) Debug(msg string, args ...any) { l.log(slog.LevelDebug, "DEBUG", msg, args) }
+func (l stdLogger) Info(msg string, args ...any)  { l.log(slog.LevelInfo, "INFO", msg, args) }
+func (l stdLogger) Warn(msg string, args ...any)  { l.log(slog.LevelWarn, "WARNING", msg, args) }
+
+func (l stdLogger) Enabled(_ context.Context, level slog.Level) bool { return level >= l.level }
+
+func (l stdLogger) log(level slog.Level, prefix, msg string, args []any) {
+	if !l.Enabled(context.Background(), level) {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(prefix + ": " + msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	log.Println(b.String())
+}
+
+// discardLogger drops all the messages.
+type discardLogger struct{}
+
+func (discardLogger) Debug(string, ...any) {}
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Warn(string, ...any)  {}
+
+func (discardLogger) Enabled(context.Context, slog.Level) bool { return false }
+
+// levelLogger is implemented by the loggers that report their enabled levels, like *slog.Logger.
+type levelLogger interface {
+	Enabled(ctx context.Context, level slog.Level) bool
+}
+
+// logEnabled reports whether the logger of the mapping handles the level.
+func logEnabled(state *mappingState, level slog.Level) bool {
+	l, ok := state.mapper.logger.(levelLogger)
+	return !ok || l.Enabled(state.ctx, level)
+}
+
+// SetLogger sets the logger of the default Mapper, which is used by the package level functions
+// and when registering rules, keeping its other options. A nil logger discards all the messages.
+func SetLogger(l Logger) {
+	m := *defaultMapper()
+	WithLogger(l)(&m)
+	SetDefaultMapper(&m)
+}
+
+// SetLogWarning turns on (true) or off (false) the warning log messages of the default Mapper. Default is on (true).
+//
+// Deprecated: use SetLogger, e.g. SetLogger(NewStdLogger(slog.LevelError)) to turn off the warnings.
 func SetLogWarning(b bool) {
-	setLogOnce.Do(func() {
-		logWarning = b
-	})
+	if b {
+		SetLogger(NewStdLogger(slog.LevelInfo))
+		return
+	}
+	SetLogger(NewStdLogger(slog.LevelError))
 }
 
-func logTargetFieldWithoutMappingValueInSource(key rulesKey, targetFieldName string) {
-	if logWarning {
-		log.Printf("WARNING: (%s -> %s) No mapping found for name '%s'.\n",
-			key.source, key.target, targetFieldName,
-		)
+// logAttrs returns the structured attributes of a mapping log message.
+func logAttrs(key rulesKey, path Path, args ...any) []any {
+	return append([]any{"source", key.source.String(), "target", key.target.String(), "path", string(path)}, args...)
+}
+
+func logFieldMappedByName(state *mappingState, key rulesKey, targetFieldName string) {
+	if !logEnabled(state, slog.LevelDebug) {
+		return
 	}
+	state.mapper.logger.Debug(
+		fmt.Sprintf("(%s -> %s) Field '%s' mapped by name.", key.source, key.target, targetFieldName),
+		logAttrs(key, state.path())...,
+	)
 }
 
-func logIgnoringMappingForIncompatibleTypes(key rulesKey, targetFieldName string, sourceValue, targetValue reflect.Value) {
-	if logWarning {
-		log.Printf(
-			"WARNING: (%s -> %s) Ignoring mapping for name '%s' (%s) to (%s), cause: Incompatible types.\n",
-			key.source, key.target, targetFieldName, sourceValue.Type(), targetValue.Type(),
-		)
+func logTargetFieldWithoutMappingValueInSource(state *mappingState, key rulesKey, targetFieldName string) {
+	if !logEnabled(state, slog.LevelWarn) {
+		return
+	}
+	state.mapper.logger.Warn(
+		fmt.Sprintf("(%s -> %s) No mapping found for name '%s'.", key.source, key.target, targetFieldName),
+		logAttrs(key, state.path())...,
+	)
+}
+
+func logIgnoringMappingForIncompatibleTypes(state *mappingState, key rulesKey, targetFieldName string, sourceValue, targetValue reflect.Value) {
+	if !logEnabled(state, slog.LevelWarn) {
+		return
 	}
+	state.mapper.logger.Warn(
+		fmt.Sprintf(
+			"(%s -> %s) Ignoring mapping for name '%s' (%s) to (%s), cause: Incompatible types.",
+			key.source, key.target, targetFieldName, sourceValue.Type(), targetValue.Type(),
+		),
+		logAttrs(key, state.path())...,
+	)
 }
 
-func logPassingZeroValue(method, argType reflect.Type, argPosition int) {
-	if logWarning {
-		log.Printf(
-			"WARNING: Passing 'ZeroValue' in custom function (%s) for argument of type '%s' in position %d.\n",
+func logPassingZeroValue(state *mappingState, method, argType reflect.Type, argPosition int) {
+	if !logEnabled(state, slog.LevelWarn) {
+		return
+	}
+	state.mapper.logger.Warn(
+		fmt.Sprintf(
+			"Passing 'ZeroValue' in custom function (%s) for argument of type '%s' in position %d.",
 			method, argType, argPosition,
-		)
+		),
+		"path", string(state.path()),
+	)
+}
+
+func logSkippedUnexported(state *mappingState, key rulesKey, targetFieldName string) {
+	if !logEnabled(state, slog.LevelDebug) {
+		return
 	}
+	state.mapper.logger.Debug(
+		fmt.Sprintf("(%s -> %s) Unexported field '%s' skipped.", key.source, key.target, targetFieldName),
+		logAttrs(key, state.path())...,
+	)
+}
+
+func logUnsupportedUnexported(state *mappingState, msg string, args ...any) {
+	if !logEnabled(state, slog.LevelWarn) {
+		return
+	}
+	state.mapper.logger.Warn(msg, append([]any{"path", string(state.path())}, args...)...)
+}
+
+func logCheckingRules(key rulesKey) {
+	defaultMapper().logger.Debug(
+		fmt.Sprintf("Checking rules for mapping (%s -> %s).", key.source.String(), key.target.String()),
+		"source", key.source.String(), "target", key.target.String(),
+	)
+}
+
+func logIgnoredField(key rulesKey, targetFieldName string) {
+	defaultMapper().logger.Info(
+		fmt.Sprintf("(%s -> %s) Field '%s' is marked as ignored.", key.source.String(), key.target.String(), targetFieldName),
+		"source", key.source.String(), "target", key.target.String(), "path", targetFieldName,
+	)
 }
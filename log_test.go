@@ -2,13 +2,30 @@ package structsconv
 
 import (
 	"bytes"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
 )
 
+// recordLogger records the messages by level.
+type recordLogger struct {
+	debug, info, warn []string
+}
+
+func (l *recordLogger) Debug(msg string, args ...any) {
+	l.debug = append(l.debug, fmt.Sprint(msg, args))
+}
+func (l *recordLogger) Info(msg string, args ...any) { l.info = append(l.info, fmt.Sprint(msg, args)) }
+func (l *recordLogger) Warn(msg string, args ...any) { l.warn = append(l.warn, fmt.Sprint(msg, args)) }
+
+func testLogState(l Logger) *mappingState {
+	return &mappingState{mapper: New(WithLogger(l))}
+}
+
 func Test_Log_on(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
@@ -24,20 +41,55 @@ func Test_Log_on(t *testing.T) {
 			B int
 		}{}),
 	}
-	wantContains := "(struct { A int } -> struct { B int }) No mapping found for name 'A'"
-	logTargetFieldWithoutMappingValueInSource(rk, "A")
+	wantContains := "WARNING: (struct { A int } -> struct { B int }) No mapping found for name 'A'"
+	logTargetFieldWithoutMappingValueInSource(&mappingState{mapper: defaultMapper()}, rk, "A")
 	if !strings.Contains(buf.String(), wantContains) {
 		t.Errorf("log.Printf() = %q; want %q", buf.String(), wantContains)
 	}
 }
 
-func Test_logTargetFieldWithoutMappingValueInSource_off(t *testing.T) {
+func Test_SetLogWarning_toggle(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
 	defer log.SetOutput(os.Stderr)
+	defer SetLogWarning(true)
 
-	logWarning = false
-	defer func() { logWarning = true }()
+	rk := buildKey(AStruct{}, BStruct{})
+
+	SetLogWarning(false)
+	logTargetFieldWithoutMappingValueInSource(&mappingState{mapper: defaultMapper()}, rk, "A")
+	if buf.Len() != 0 {
+		t.Errorf("log.Printf() = %q; want empty", buf.String())
+	}
+
+	SetLogWarning(true)
+	logTargetFieldWithoutMappingValueInSource(&mappingState{mapper: defaultMapper()}, rk, "A")
+	if !strings.Contains(buf.String(), "No mapping found for name 'A'") {
+		t.Errorf("log.Printf() = %q; want warning", buf.String())
+	}
+}
+
+func Test_SetLogger_keepsOptions(t *testing.T) {
+	backup := defaultMapper()
+	defer SetDefaultMapper(backup)
+
+	SetDefaultMapper(New(WithMaxDepth(3), WithDeepCopy(true)))
+	l := &recordLogger{}
+	SetLogger(l)
+	if m := defaultMapper(); m.logger != l || m.maxDepth != 3 || !m.deepCopy {
+		t.Errorf("SetLogger() default Mapper = %+v, want the logger and the previous options", m)
+	}
+
+	SetDefaultMapper(nil)
+	if m := defaultMapper(); m.maxDepth != 0 || !m.allowUnexported {
+		t.Errorf("SetDefaultMapper(nil) default Mapper = %+v, want the default options", m)
+	}
+}
+
+func Test_logTargetFieldWithoutMappingValueInSource_off(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
 
 	rk := rulesKey{
 		source: reflect.TypeOf(struct {
@@ -47,10 +99,9 @@ func Test_logTargetFieldWithoutMappingValueInSource_off(t *testing.T) {
 			B int
 		}{}),
 	}
-	wantContains := ""
-	logTargetFieldWithoutMappingValueInSource(rk, "A")
-	if !strings.Contains(buf.String(), wantContains) {
-		t.Errorf("log.Printf() = %q; want %q", buf.String(), wantContains)
+	logTargetFieldWithoutMappingValueInSource(testLogState(NewStdLogger(slog.LevelError)), rk, "A")
+	if buf.Len() != 0 {
+		t.Errorf("log.Printf() = %q; want empty", buf.String())
 	}
 }
 
@@ -59,9 +110,6 @@ func Test_logIgnoringMappingForIncompatibleTypes_off(t *testing.T) {
 	log.SetOutput(&buf)
 	defer log.SetOutput(os.Stderr)
 
-	logWarning = false
-	defer func() { logWarning = true }()
-
 	source := struct {
 		A int
 	}{}
@@ -74,10 +122,9 @@ func Test_logIgnoringMappingForIncompatibleTypes_off(t *testing.T) {
 		source: reflect.TypeOf(source),
 		target: reflect.TypeOf(target),
 	}
-	wantContains := ""
-	logIgnoringMappingForIncompatibleTypes(rk, "A", reflect.ValueOf(source), reflect.ValueOf(target))
-	if !strings.Contains(buf.String(), wantContains) {
-		t.Errorf("log.Printf() = %q; want %q", buf.String(), wantContains)
+	logIgnoringMappingForIncompatibleTypes(testLogState(nil), rk, "A", reflect.ValueOf(source), reflect.ValueOf(target))
+	if buf.Len() != 0 {
+		t.Errorf("log.Printf() = %q; want empty", buf.String())
 	}
 }
 
@@ -86,16 +133,60 @@ func Test_logPassingZeroValue_off(t *testing.T) {
 	log.SetOutput(&buf)
 	defer log.SetOutput(os.Stderr)
 
-	logWarning = false
-	defer func() { logWarning = true }()
-
-	wantContains := ""
-
 	ft := reflect.TypeOf(func(i int) {})
 	at := reflect.TypeOf(1)
 
-	logPassingZeroValue(ft, at, 0)
-	if !strings.Contains(buf.String(), wantContains) {
-		t.Errorf("log.Printf() = %q; want %q", buf.String(), wantContains)
+	logPassingZeroValue(testLogState(NewStdLogger(slog.LevelError)), ft, at, 0)
+	if buf.Len() != 0 {
+		t.Errorf("log.Printf() = %q; want empty", buf.String())
+	}
+}
+
+func Test_Mapper_log_levels(t *testing.T) {
+	type nested struct{ Field string }
+	type source struct {
+		Name   string
+		Age    string
+		Nested nested
+	}
+	type target struct {
+		Name    string
+		Age     int
+		Missing string
+		Nested  nested
+	}
+
+	l := &recordLogger{}
+	if err := New(WithLogger(l)).MapE(&source{Name: "name"}, &target{}); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+
+	if len(l.debug) != 2 || !strings.Contains(l.debug[0], "Field 'Name' mapped by name") {
+		t.Errorf("debug messages = %v, want 'Name' and 'Nested' mapped by name", l.debug)
+	}
+	if len(l.warn) != 2 ||
+		!strings.Contains(l.warn[0], "Ignoring mapping for name 'Age'") ||
+		!strings.Contains(l.warn[1], "No mapping found for name 'Missing'") {
+		t.Errorf("warn messages = %v, want 'Age' incompatible and 'Missing' not found", l.warn)
+	}
+}
+
+func Test_NewSlogLogger_attrs(t *testing.T) {
+	type source struct{ Name string }
+	type target struct{ Name, Missing string }
+
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	if err := New(WithLogger(l)).MapE(&source{Name: "name"}, &target{}); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+
+	for _, want := range []string{"level=WARN", "source=structsconv.source", "target=structsconv.target", "path=Missing"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("slog output = %q; want %q", buf.String(), want)
+		}
+	}
+	if strings.Contains(buf.String(), "level=DEBUG") {
+		t.Errorf("slog output = %q; want no debug messages", buf.String())
 	}
 }
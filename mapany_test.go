@@ -0,0 +1,115 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type bookDto struct {
+	Title  string
+	Author string
+}
+
+type book struct {
+	Title string
+	Label string
+}
+
+func registerBookRules(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: bookDto{},
+		Target: book{},
+		Rules: RulesSet{
+			"Label": func(root *bookDto, prefix string) (string, error) {
+				if root.Author == "" {
+					return "", errors.New("no author")
+				}
+				return prefix + root.Title + " by " + root.Author, nil
+			},
+		},
+	})
+}
+
+func Test_MapAny(t *testing.T) {
+	registerBookRules(t)
+	dtos := []bookDto{{Title: "t1", Author: "a1"}, {Title: "t2", Author: "a2"}}
+
+	t.Run("slice", func(t *testing.T) {
+		got := []book{{Title: "replaced"}}
+		if err := MapAny(&dtos, &got, "> "); err != nil {
+			t.Fatalf("MapAny() error = %v", err)
+		}
+		want := []book{{Title: "t1", Label: "> t1 by a1"}, {Title: "t2", Label: "> t2 by a2"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MapAny() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("slice of pointers", func(t *testing.T) {
+		src := []*bookDto{&dtos[0], nil}
+		var got []*book
+		if err := MapAny(&src, &got, "> "); err != nil {
+			t.Fatalf("MapAny() error = %v", err)
+		}
+		want := []*book{{Title: "t1", Label: "> t1 by a1"}, nil}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MapAny() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("array", func(t *testing.T) {
+		src := [2]bookDto{dtos[0], dtos[1]}
+		var got [2]book
+		if err := MapAny(&src, &got, "> "); err != nil {
+			t.Fatalf("MapAny() error = %v", err)
+		}
+		want := [2]book{{Title: "t1", Label: "> t1 by a1"}, {Title: "t2", Label: "> t2 by a2"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MapAny() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("map", func(t *testing.T) {
+		src := map[string]bookDto{"b1": dtos[0]}
+		var got map[string]book
+		if err := MapAny(&src, &got, "> "); err != nil {
+			t.Fatalf("MapAny() error = %v", err)
+		}
+		want := map[string]book{"b1": {Title: "t1", Label: "> t1 by a1"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MapAny() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		var got book
+		if err := MapAny(&dtos[1], &got, "> "); err != nil {
+			t.Fatalf("MapAny() error = %v", err)
+		}
+		if want := (book{Title: "t2", Label: "> t2 by a2"}); got != want {
+			t.Errorf("MapAny() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func Test_MapAny_errors(t *testing.T) {
+	registerBookRules(t)
+	src := []bookDto{{Title: "t1", Author: "a1"}, {Title: "t2"}}
+	var got []book
+	err := MapAny(&src, &got, "")
+	if want := "mapping error: field '[1].Label': no author"; err == nil || err.Error() != want {
+		t.Errorf("MapAny() error = %v, want %s", err, want)
+	}
+
+	ints := []int{1}
+	var strs []string
+	err = MapAny(&ints, &strs)
+	if want := "rules error: source and target must be pointers to structs, or to slices, arrays or maps of structs, got (*[]int) to (*[]string)"; err == nil || err.Error() != want {
+		t.Errorf("MapAny() error = %v, want %s", err, want)
+	}
+
+	if err := MapAny(src, &got); err == nil || err.Error() != "rules error: source must be a pointer" {
+		t.Errorf("MapAny() error = %v, want source must be a pointer", err)
+	}
+}
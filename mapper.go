@@ -0,0 +1,236 @@
+package structsconv
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+)
+
+// Mapper maps structs using the registered rules, with its own options.
+//
+// The package level functions (Map, MapE, MapContext) use the default Mapper.
+type Mapper struct {
+	logger          Logger
+	deepCopy        bool
+	disallowCycles  bool
+	maxDepth        int
+	parallelism     int
+	allowUnexported bool
+}
+
+// Option configures a Mapper.
+type Option func(*Mapper)
+
+// WithLogger sets the logger of the Mapper. A nil logger discards all the messages.
+func WithLogger(l Logger) Option {
+	return func(m *Mapper) {
+		if l == nil {
+			l = discardLogger{}
+		}
+		m.logger = l
+	}
+}
+
+// WithDeepCopy makes the Mapper allocate new slices, maps and pointees when the source and target values have
+// the same type, instead of sharing them. Structs of the same type are mapped field by field, applying the rules
+// registered for the pair.
+func WithDeepCopy(deepCopy bool) Option {
+	return func(m *Mapper) {
+		m.deepCopy = deepCopy
+	}
+}
+
+// WithCycles sets whether pointer cycles in the source are allowed, they are by default.
+//
+// Allowed cycles are reproduced in the target, since every source pointer is mapped once and the target pointer is
+// reused for the other references to it. Disallowed cycles make the mapping fail with an error wrapping ErrCycle.
+func WithCycles(allow bool) Option {
+	return func(m *Mapper) {
+		m.disallowCycles = !allow
+	}
+}
+
+// WithMaxDepth makes the mapping fail with an error wrapping ErrMaxDepth when the structs are nested deeper than
+// depth, the root struct being at depth 1. A depth of 0, the default, means no limit.
+func WithMaxDepth(depth int) Option {
+	return func(m *Mapper) {
+		m.maxDepth = depth
+	}
+}
+
+// New creates a Mapper with the given options.
+//
+// By default, the Mapper logs info and warning messages with the standard log package.
+func New(opts ...Option) *Mapper {
+	m := &Mapper{logger: NewStdLogger(slog.LevelInfo), allowUnexported: true}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// defaultMapperPtr holds the Mapper used by the package level functions.
+var defaultMapperPtr atomic.Pointer[Mapper]
+
+func init() {
+	defaultMapperPtr.Store(New())
+}
+
+// defaultMapper returns the Mapper used by the package level functions.
+func defaultMapper() *Mapper {
+	return defaultMapperPtr.Load()
+}
+
+// SetDefaultMapper replaces the Mapper used by the package level functions, and when registering rules, to change
+// its options. A nil Mapper restores the default options, see New.
+func SetDefaultMapper(m *Mapper) {
+	if m == nil {
+		m = New()
+	}
+	defaultMapperPtr.Store(m)
+}
+
+// Map maps the source structure to a destination structure; source and target must be pointers to structs.
+//
+// The value will be mapped into the target structure. Map panics if the mapping fails, use MapE to get the error instead.
+func (m *Mapper) Map(source interface{}, target interface{}, args ...interface{}) {
+	if err := m.MapE(source, target, args...); err != nil {
+		log.Panicf("ERROR: %s", err)
+	}
+}
+
+// MapE maps the source structure to a destination structure; source and target must be pointers to structs.
+//
+// The value will be mapped into the target structure. Any error returned by a rule function aborts the mapping
+// and is returned wrapped in a *MappingError with the path of the target field.
+func (m *Mapper) MapE(source interface{}, target interface{}, args ...interface{}) error {
+	return m.MapContext(context.Background(), source, target, args...)
+}
+
+// MapContext works like MapE, but the given context is passed to the rule functions that request a context.Context,
+// and the mapping is aborted with ctx.Err() when the context is done.
+//
+// A panic during the mapping, in a rule function included, is recovered and returned as an error wrapping ErrPanic.
+func (m *Mapper) MapContext(ctx context.Context, source interface{}, target interface{}, args ...interface{}) error {
+	sourceV := reflect.ValueOf(source)
+	targetV := reflect.ValueOf(target)
+	if err := checkRootValuesTypes(sourceV, targetV); err != nil { // check if the source and target are pointers
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	state, err := m.newState(ctx, sourceV, args)
+	if err != nil {
+		return err
+	}
+	state.addPointer(sourceV, targetV)
+	return state.run(func() error {
+		return structToStruct(sourceV.Elem(), targetV.Elem(), sourceV.Elem(), state)
+	})
+}
+
+// MapAny works like MapE, but source and target can also be pointers to slices, arrays or maps of structs,
+// which are mapped item by item. The target slice or map is replaced, and collections of the same type are assigned.
+//
+// Every item of a root slice, array or map is the root source of its own mapping: it is the value injected in the
+// rule functions that request the root source type, or a pointer to it.
+func (m *Mapper) MapAny(source interface{}, target interface{}, args ...interface{}) error {
+	return m.MapAnyContext(context.Background(), source, target, args...)
+}
+
+// MapAnyContext works like MapAny, with the context handling of MapContext.
+func (m *Mapper) MapAnyContext(ctx context.Context, source interface{}, target interface{}, args ...interface{}) error {
+	sourceV := reflect.ValueOf(source)
+	targetV := reflect.ValueOf(target)
+	r := loadRegistry()
+	if err := checkRootAnyValuesTypes(r, sourceV, targetV); err != nil {
+		return err
+	}
+	if sourceV.Elem().Kind() == reflect.Struct {
+		return m.MapContext(ctx, source, target, args...)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	state, err := m.newState(ctx, sourceV, args)
+	if err != nil {
+		return err
+	}
+	state.registry = r // the registry the root types were checked with
+	return state.run(func() error {
+		s, t := sourceV.Elem(), targetV.Elem()
+		if s.Kind() == reflect.Slice {
+			if s.IsNil() {
+				t.Set(reflect.Zero(t.Type()))
+				return nil
+			}
+			t.Set(reflect.MakeSlice(t.Type(), 0, s.Len()))
+		}
+		_, err := fieldToField(s, t, state)
+		return err
+	})
+}
+
+// newState creates the state of a mapping call, sourceV is the pointer to the root source.
+func (m *Mapper) newState(ctx context.Context, sourceV reflect.Value, args []interface{}) (*mappingState, error) {
+	userArgs, named, err := splitArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return &mappingState{
+		mapper:   m,
+		ctx:      ctx,
+		args:     groupArgs(rootArgs(sourceV, userArgs)),
+		userArgs: userArgs,
+		pointers: &mappedPointers{pointers: make(map[pointerKey]*mappedPointer)},
+		argTypes: getArgTypes(userArgs),
+		named:    named,
+		registry: loadRegistry(),
+	}, nil
+}
+
+// rootArgs returns the arguments of a mapping call: the pointer to the root source, the user arguments
+// and the root source.
+func rootArgs(sourceV reflect.Value, userArgs []interface{}) []interface{} {
+	args := make([]interface{}, 0, len(userArgs)+2)
+	args = append(args, sourceV.Interface())
+	args = append(args, userArgs...)
+	return append(args, sourceV.Elem().Interface())
+}
+
+// setRootItem makes the item of a root slice, array or map the root source of its mapping, see MapAny.
+// Items of nested collections are ignored.
+func (s *mappingState) setRootItem(item reflect.Value) {
+	if s.depth > 0 || (item.Kind() == reflect.Ptr && item.IsNil()) {
+		return
+	}
+	switch {
+	case item.Kind() == reflect.Ptr:
+	case item.CanAddr():
+		item = item.Addr()
+	default: // map items are not addressable
+		p := reflect.New(item.Type())
+		p.Elem().Set(item)
+		item = p
+	}
+	s.args = groupArgs(rootArgs(item, s.userArgs))
+}
+
+// run calls the mapping function f, recovering its panics as errors wrapping ErrPanic.
+func (s *mappingState) run(f func() error) error {
+	return s.runFrom(0, f)
+}
+
+// runFrom works like run, the path of the errors starts at the frame of the given position.
+func (s *mappingState) runFrom(frame int, f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &MappingError{Path: string(pathOf(s.frames[frame:])), Err: fmt.Errorf("%w: %v", ErrPanic, p)}
+		}
+	}()
+	return f()
+}
@@ -0,0 +1,102 @@
+package structsconv
+
+import "sync"
+
+// WithParallelism makes the Mapper split the mapping of the items of slices and arrays across n workers, keeping
+// the order of the items. The first error stops the mapping of the remaining items.
+//
+// Only the outermost slices and arrays are split, the nested ones are mapped by the worker of their item.
+// Rule functions may be called concurrently. A value of 0 or 1, the default, maps the items sequentially.
+func WithParallelism(n int) Option {
+	return func(m *Mapper) {
+		m.parallelism = n
+	}
+}
+
+// fork returns the state of a parallel worker, with its own position.
+func (s *mappingState) fork() *mappingState {
+	w := *s
+	w.frames = append(make([]mappingFrame, 0, len(s.frames)+8), s.frames...)
+	w.forkedOf = s
+	w.params = nil
+	return &w
+}
+
+// descendsFrom reports whether the state is s or a worker started by s, or by its workers.
+func (s *mappingState) descendsFrom(ancestor *mappingState) bool {
+	for w := s; w != nil; w = w.forkedOf {
+		if w == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachItem calls mapItem for the items 0 to n-1 in order, or splits them across the workers of the Mapper
+// parallelism. It returns the error of the first item that failed.
+func (s *mappingState) forEachItem(n int, mapItem func(i int, state *mappingState) error) error {
+	workers := s.mapper.parallelism
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 || s.forkedOf != nil {
+		for i := 0; i < n; i++ {
+			if err := checkContext(s.ctx); err != nil {
+				return err
+			}
+			if err := mapItem(i, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the items are ordered after the values already mapped, and the items of the collections mapped before
+	s.pointers.mu.Lock()
+	base := s.pointers.orders + 1
+	s.pointers.orders += n
+	s.pointers.mu.Unlock()
+
+	var (
+		mu       sync.Mutex
+		next     int
+		firstErr error
+		errIndex = n
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(state *mappingState) {
+			defer wg.Done()
+			for {
+				// the items before a failed one are still mapped, so the error returned is the one of the first item
+				mu.Lock()
+				i := next
+				next++
+				stop := i >= errIndex
+				mu.Unlock()
+				if stop {
+					return
+				}
+				if err := checkContext(state.ctx); err != nil {
+					mu.Lock()
+					firstErr, errIndex = err, -1
+					mu.Unlock()
+					return
+				}
+				state.order = base + i
+				if err := state.runFrom(len(s.frames), func() error { return mapItem(i, state) }); err != nil {
+					mu.Lock()
+					if i < errIndex {
+						firstErr, errIndex = err, i
+					}
+					mu.Unlock()
+					return
+				}
+			}
+		}(s.fork())
+	}
+	wg.Wait()
+	s.order = base + n - 1 // the values mapped after the items
+	return firstErr
+}
@@ -0,0 +1,188 @@
+package structsconv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type parallelItemSource struct {
+	ID   int
+	Tags []parallelTagSource
+	Ref  *parallelItemSource
+}
+
+type parallelTagSource struct{ Name string }
+
+type parallelItemTarget struct {
+	ID    int
+	Label string
+	Tags  []parallelTagTarget
+	Ref   *parallelItemTarget
+}
+
+type parallelTagTarget struct{ Name string }
+
+type parallelSource struct {
+	Items []parallelItemSource
+	Array [64]parallelTagSource
+}
+
+type parallelTarget struct {
+	Items []parallelItemTarget
+	Array [64]parallelTagTarget
+}
+
+func registerParallelRules(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: parallelItemSource{},
+		Target: parallelItemTarget{},
+		Rules: RulesSet{
+			"Label": func(s parallelItemSource, i Index, p Path) (string, error) {
+				if s.ID < 0 {
+					return "", fmt.Errorf("invalid id %d", s.ID)
+				}
+				if s.ID == 7 && p == "Items[7].Label" { // the later items sharing its Ref reach it first
+					time.Sleep(20 * time.Millisecond)
+				}
+				return fmt.Sprintf("%d:%d:%s", s.ID, i, p), nil
+			},
+		},
+	})
+}
+
+func newParallelSource(n int) parallelSource {
+	var src parallelSource
+	src.Items = make([]parallelItemSource, n)
+	for i := range src.Items {
+		src.Items[i] = parallelItemSource{ID: i, Tags: []parallelTagSource{{Name: fmt.Sprint("tag", i)}}}
+	}
+	src.Items[1].Ref = &src.Items[0] // shared with the source of Items[0].Ref
+	src.Items[0].Ref = &src.Items[0]
+	for i := 7; i < n; i += n / 10 { // shared by items far apart, mapped by different workers
+		src.Items[i].Ref = &src.Items[n-1]
+	}
+	for i := range src.Array {
+		src.Array[i] = parallelTagSource{Name: fmt.Sprint("array", i)}
+	}
+	return src
+}
+
+func Test_Map_parallelism(t *testing.T) {
+	registerParallelRules(t)
+	src := newParallelSource(1000)
+
+	var want parallelTarget
+	if err := MapE(&src, &want); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	for _, n := range []int{2, 8, 2000} {
+		for run := 0; run < 5; run++ {
+			var got parallelTarget
+			if err := New(WithParallelism(n)).MapE(&src, &got); err != nil {
+				t.Fatalf("parallelism %d: MapE() error = %v", n, err)
+			}
+			if got.Items[0].Ref != got.Items[1].Ref || got.Items[7].Ref != got.Items[907].Ref {
+				t.Fatalf("parallelism %d: shared references not preserved", n)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("parallelism %d: result differs from the sequential mapping", n)
+			}
+		}
+	}
+	if want.Items[10].Label != "10:10:Items[10].Label" {
+		t.Errorf("Label = %s", want.Items[10].Label)
+	}
+	if label := want.Items[907].Ref.Label; label != "999:7:Items[7].Ref.Label" { // mapped by the first item reaching it
+		t.Errorf("shared Label = %s", label)
+	}
+}
+
+func Test_Map_parallelism_firstError(t *testing.T) {
+	registerParallelRules(t)
+	src := newParallelSource(1000)
+	src.Items[300].ID = -1
+	src.Items[700].ID = -1
+
+	var got parallelTarget
+	err := New(WithParallelism(8)).MapE(&src, &got)
+	if want := "mapping error: field 'Items[300].Label': invalid id -1"; err == nil || err.Error() != want {
+		t.Errorf("MapE() error = %v, want %s", err, want)
+	}
+}
+
+func Test_Map_parallelism_context(t *testing.T) {
+	registerParallelRules(t)
+	src := newParallelSource(100)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var got parallelTarget
+	err := New(WithParallelism(4)).MapContext(ctx, &src, &got)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("MapContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_Map_parallelism_panic(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: parallelTagSource{},
+		Target: parallelTagTarget{},
+		Rules: RulesSet{
+			"Name": func(s parallelTagSource) string {
+				if s.Name == "array3" {
+					panic("boom")
+				}
+				return s.Name
+			},
+		},
+	})
+
+	src := newParallelSource(2)
+	var got parallelTarget
+	err := New(WithParallelism(4)).MapE(&src, &got)
+	var mErr *MappingError
+	if !errors.Is(err, ErrPanic) || !errors.As(err, &mErr) || mErr.Path != "Array[3].Name" {
+		t.Errorf("MapE() error = %v, want panic at 'Array[3].Name'", err)
+	}
+}
+
+func Test_Map_parallelism_sharedPanic(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: parallelItemSource{},
+		Target: parallelItemTarget{},
+		Rules: RulesSet{
+			"Label": func(s parallelItemSource, p Path) string {
+				if s.ID == 99 {
+					panic("boom")
+				}
+				if p == "Items[1].Label" { // the later item sharing its Ref reaches it first
+					time.Sleep(50 * time.Millisecond)
+				}
+				return ""
+			},
+		},
+	})
+
+	src := newParallelSource(6)
+	ref := &parallelItemSource{ID: 99}
+	src.Items[0].Ref, src.Items[1].Ref, src.Items[5].Ref = nil, ref, ref
+
+	errs := make(chan error, 1)
+	go func() {
+		var got parallelTarget
+		errs <- New(WithParallelism(4)).MapE(&src, &got)
+	}()
+	select {
+	case err := <-errs:
+		var mErr *MappingError
+		if !errors.Is(err, ErrPanic) || !errors.As(err, &mErr) || mErr.Path != "Items[1].Ref.Label" {
+			t.Errorf("MapE() error = %v, want panic at 'Items[1].Ref.Label'", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("MapE() did not return")
+	}
+}
@@ -0,0 +1,117 @@
+package structsconv
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// registry is a snapshot of the registered rules, strategies and implementations. A published registry is never
+// modified: the registration functions change a copy and publish it, so the mappings in progress keep reading the
+// snapshot they started with.
+type registry struct {
+	rules           mapperRulesRegistry
+	strategies      map[rulesKey]*rulesStrategy                    // only the definitions with a constructor, setters or hooks have one
+	implementations map[reflect.Type]map[reflect.Type]reflect.Type // by target interface and source concrete type
+}
+
+var (
+	// currentRegistry is the published registry, see loadRegistry.
+	currentRegistry atomic.Pointer[registry]
+	// registryMu serializes the changes of the registry.
+	registryMu sync.Mutex
+	// emptyRegistry is the registry before the first registration.
+	emptyRegistry = &registry{
+		rules:           make(mapperRulesRegistry),
+		strategies:      make(map[rulesKey]*rulesStrategy),
+		implementations: make(map[reflect.Type]map[reflect.Type]reflect.Type),
+	}
+)
+
+// loadRegistry returns the published registry, which must not be modified.
+func loadRegistry() *registry {
+	if r := currentRegistry.Load(); r != nil {
+		return r
+	}
+	return emptyRegistry
+}
+
+// updateRegistry publishes a copy of the registry changed by f. The registry is unchanged if f panics.
+func updateRegistry(f func(r *registry)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	r := loadRegistry().clone()
+	f(r)
+	currentRegistry.Store(r)
+}
+
+// clone returns a copy of the registry that can be modified.
+func (r *registry) clone() *registry {
+	c := &registry{
+		rules:           maps.Clone(r.rules),
+		strategies:      maps.Clone(r.strategies),
+		implementations: make(map[reflect.Type]map[reflect.Type]reflect.Type, len(r.implementations)),
+	}
+	for iface, impls := range r.implementations {
+		c.implementations[iface] = maps.Clone(impls)
+	}
+	return c
+}
+
+// Unregister removes the rules registered for mapping source to target, given as values like in RulesDefinition.
+// Returns false if there were none. The pair is then mapped by default.
+//
+// The mappings in progress keep using the rules they started with.
+func Unregister(source, target interface{}) bool {
+	key := buildKey(source, target)
+	var exists bool
+	updateRegistry(func(r *registry) {
+		_, exists = r.rules[key]
+		delete(r.rules, key)
+		delete(r.strategies, key)
+	})
+	return exists
+}
+
+// Replace registers a RulesDefinition, or a pointer to it, replacing the rules of its source and target if they
+// are already registered. The current rules are kept if the definition is not valid, which panics as in
+// RegisterRulesDefinitions.
+//
+// The mappings in progress keep using the rules they started with, the new rules apply to the next ones.
+func Replace(definition interface{}) {
+	d := parseRulesDefinition(definition)
+	updateRegistry(func(r *registry) {
+		setRules(r, buildKey(d.Source, d.Target), d)
+	})
+}
+
+// WithRules replaces the rules of the definition, as Replace, while f runs, then restores the previous rules of
+// its source and target, or unregisters them if there were none, even if f panics.
+//
+// It is intended for tests that tweak a mapping, which must not run in parallel with other tests mapping the pair.
+func WithRules(definition interface{}, f func()) {
+	d := parseRulesDefinition(definition)
+	key := buildKey(d.Source, d.Target)
+	var (
+		rules    RulesSet
+		exists   bool
+		strategy *rulesStrategy
+	)
+	updateRegistry(func(r *registry) {
+		rules, exists = r.rules[key]
+		strategy = r.strategies[key]
+		setRules(r, key, d)
+	})
+	defer updateRegistry(func(r *registry) {
+		delete(r.rules, key)
+		delete(r.strategies, key)
+		if exists {
+			r.rules[key] = rules
+		}
+		if strategy != nil {
+			r.strategies[key] = strategy
+		}
+	})
+	f()
+}
@@ -0,0 +1,173 @@
+package structsconv
+
+import (
+	"fmt"
+	"testing"
+)
+
+type shapeDto struct{ Side int }
+
+type shape interface{ Area() int }
+
+type square struct{ Side int }
+
+func (s square) Area() int { return s.Side * s.Side }
+
+type drawingDto struct {
+	Name   string
+	Shape  shapeDto
+	Author employeeDto
+}
+
+type drawing struct {
+	Name   string
+	Shape  shape
+	Author employee
+}
+
+func mapEmployee(t *testing.T) employee {
+	t.Helper()
+	var got employee
+	if err := New(WithLogger(nil)).MapE(&employeeDto{UserID: "u1", FullName: "Ann", CreatedBy: "bob"}, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	return got
+}
+
+func Test_Unregister(t *testing.T) {
+	withRegistry(t, RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: identityRules})
+
+	if got := mapEmployee(t); got.ID != "u1" {
+		t.Fatalf("MapE() = %+v, want ID u1", got)
+	}
+	if !Unregister(employeeDto{}, employee{}) {
+		t.Errorf("Unregister() = false, want true")
+	}
+	if got := mapEmployee(t); got.ID != "" || got.CreatedBy != "bob" {
+		t.Errorf("MapE() = %+v, want the default mapping", got)
+	}
+	if Unregister(employeeDto{}, employee{}) {
+		t.Errorf("Unregister() = true, want false")
+	}
+	RegisterRulesDefinitions(RulesDefinition{Source: employeeDto{}, Target: employee{}}) // no longer a duplicate
+}
+
+func Test_Replace(t *testing.T) {
+	withRegistry(t)
+
+	Replace(RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: identityRules})
+	if got := mapEmployee(t); got.ID != "u1" || got.Name != "" {
+		t.Fatalf("MapE() = %+v, want ID u1", got)
+	}
+
+	Replace(&RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: RulesSet{"Name": "FullName"}})
+	if got := mapEmployee(t); got.ID != "" || got.Name != "Ann" {
+		t.Errorf("MapE() = %+v, want the replaced rules", got)
+	}
+
+	var f = func() {
+		Replace(RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: RulesSet{"Name": "Missing"}})
+	}
+	assertPanic(f, "Missing", t)
+	if got := mapEmployee(t); got.Name != "Ann" {
+		t.Errorf("MapE() = %+v, want the rules kept after an invalid definition", got)
+	}
+}
+
+func Test_Replace_whileMapping(t *testing.T) {
+	withRegistry(t, RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: identityRules})
+
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			var got employee
+			if err := New(WithLogger(nil)).MapE(&employeeDto{UserID: "u1", FullName: "Ann"}, &got); err != nil {
+				errs <- err
+				return
+			}
+			if got != (employee{ID: "u1"}) && got != (employee{Name: "Ann"}) {
+				errs <- fmt.Errorf("MapE() = %+v, want the rules of one definition", got)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		rules := identityRules
+		if i%2 == 0 {
+			rules = RulesSet{"Name": "FullName"}
+		}
+		Replace(RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: rules})
+	}
+	close(done)
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Replace_duringMapping(t *testing.T) {
+	replaced := false
+	withRegistry(t,
+		RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: identityRules},
+		RulesDefinition{Source: drawingDto{}, Target: drawing{}, Rules: RulesSet{
+			"Name": func(d drawingDto) string {
+				if !replaced { // the fields mapped after Name keep the registry of the mapping
+					RegisterImplementation((*shape)(nil), shapeDto{}, square{})
+					Replace(RulesDefinition{Source: employeeDto{}, Target: employee{}, Rules: RulesSet{"Name": "FullName"}})
+					replaced = true
+				}
+				return d.Name
+			},
+		}},
+	)
+
+	src := drawingDto{Name: "d1", Shape: shapeDto{Side: 2}, Author: employeeDto{UserID: "u1", FullName: "Ann"}}
+	var got drawing
+	if err := New(WithLogger(nil)).MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := (drawing{Name: "d1", Author: employee{ID: "u1"}}); got != want {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+
+	got = drawing{}
+	if err := New(WithLogger(nil)).MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := (drawing{Name: "d1", Shape: square{Side: 2}, Author: employee{Name: "Ann"}}); got != want {
+		t.Errorf("MapE() = %+v, want %+v with the new registry", got, want)
+	}
+}
+
+func Test_WithRules(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source:   employeeDto{},
+		Target:   employee{},
+		Rules:    identityRules,
+		AfterMap: func(e *employee) { e.Name = "after" },
+	})
+
+	WithRules(RulesDefinition{Source: employeeDto{}, Target: employee{}, Extends: []interface{}{auditRules}}, func() {
+		if got := mapEmployee(t); got != (employee{CreatedBy: "system"}) {
+			t.Errorf("MapE() = %+v, want the scoped rules", got)
+		}
+	})
+	if got := mapEmployee(t); got != (employee{ID: "u1", Name: "after", CreatedBy: "bob"}) {
+		t.Errorf("MapE() = %+v, want the restored rules", got)
+	}
+
+	f := func() {
+		WithRules(RulesDefinition{Source: contractorDto{}, Target: contractor{}}, func() { panic("test failed") })
+	}
+	assertPanic(f, "test failed", t)
+	if _, exists := loadRegistry().rules[buildKey(contractorDto{}, contractor{})]; exists {
+		t.Errorf("rules not unregistered after WithRules")
+	}
+}
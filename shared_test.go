@@ -26,6 +26,19 @@ type TestSource struct {
 	singleListFieldS     []string
 }
 
+// withRegistry replaces the rules of the registry with the definitions for the test, the previous registry is
+// restored by its cleanup. The registered implementations are kept, and also restored.
+func withRegistry(t testing.TB, definitions ...interface{}) {
+	previous := loadRegistry()
+	t.Cleanup(func() { currentRegistry.Store(previous) })
+
+	updateRegistry(func(r *registry) {
+		clear(r.rules)
+		clear(r.strategies)
+	})
+	RegisterRulesDefinitions(definitions...)
+}
+
 func assertPanic(f func(), contain string, t *testing.T) {
 	defer func() {
 		r := recover()
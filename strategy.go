@@ -0,0 +1,151 @@
+package structsconv
+
+import (
+	"log"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rulesStrategy holds how the target of a RulesDefinition is built when it is not only by setting its fields,
+// see RulesDefinition.Constructor, RulesDefinition.Setters and the hooks.
+type rulesStrategy struct {
+	constructor   reflect.Value
+	setters       map[string]reflect.Method // setter of each target field, by field name
+	beforeMapHook reflect.Value
+	afterMapHook  reflect.Value
+}
+
+// checkStrategy checks the constructor, setters and hooks of a definition, returning nil if it has none.
+func checkStrategy(key rulesKey, d RulesDefinition) *rulesStrategy {
+	if d.Constructor == nil && !d.Setters && d.BeforeMap == nil && d.AfterMap == nil {
+		return nil
+	}
+	if key.source.Kind() != reflect.Struct || key.target.Kind() != reflect.Struct {
+		log.Panicf(
+			"ERROR: (%s -> %s) Constructor, Setters, BeforeMap and AfterMap are only supported between structs.\n",
+			key.source.String(), key.target.String(),
+		)
+	}
+	s := &rulesStrategy{
+		beforeMapHook: checkHook(key, "BeforeMap", d.BeforeMap),
+		afterMapHook:  checkHook(key, "AfterMap", d.AfterMap),
+	}
+	switch {
+	case d.Constructor != nil:
+		if len(d.Rules) > 0 || d.Setters {
+			log.Panicf(
+				"ERROR: (%s -> %s) Constructor cannot be combined with Rules or Setters.\n",
+				key.source.String(), key.target.String(),
+			)
+		}
+		s.constructor = checkConstructor(key, d.Constructor)
+	case d.Setters:
+		s.setters = getSetters(key)
+	}
+	return s
+}
+
+// checkConstructor checks that the constructor is a function returning the target type, optionally followed by an error.
+func checkConstructor(key rulesKey, constructor interface{}) reflect.Value {
+	v := reflect.ValueOf(constructor)
+	if v.Kind() != reflect.Func {
+		log.Panicf(
+			"ERROR: (%s -> %s) Constructor must be a function. Constructor = %v.\n",
+			key.source.String(), key.target.String(), constructor,
+		)
+	}
+	checkFuncParams(v.Type(), "Constructor", key)
+	checkFuncResults(v.Type(), "Constructor", key, key.target)
+	return v
+}
+
+// getSetters returns the setters of the target fields: the methods of the pointer to the target named
+// Set followed by the field name, e.g. SetName for the fields Name and name.
+//
+// A setter takes the value of the field and returns nothing or an error.
+func getSetters(key rulesKey) map[string]reflect.Method {
+	setters := make(map[string]reflect.Method)
+	ptr := reflect.PointerTo(key.target)
+	for i := 0; i < key.target.NumField(); i++ {
+		field := key.target.Field(i).Name
+		m, exists := ptr.MethodByName("Set" + upperFirst(field))
+		if !exists {
+			continue
+		}
+		if m.Type.NumIn() != 2 || m.Type.NumOut() > 1 || (m.Type.NumOut() == 1 && m.Type.Out(0) != errorType) {
+			log.Panicf(
+				"ERROR: (%s -> %s) Setter '%s' of field '%s' must take one argument and return nothing or an error. Setter = '%s'.\n",
+				key.source.String(), key.target.String(), m.Name, field, m.Type.String(),
+			)
+		}
+		setters[field] = m
+	}
+	if len(setters) == 0 {
+		log.Panicf(
+			"ERROR: (%s -> %s) Setters is set, but %s has no setter for its fields.\n",
+			key.source.String(), key.target.String(), ptr.String(),
+		)
+	}
+	return setters
+}
+
+// setter returns the setter of the target field, if the strategy has one.
+func (s *rulesStrategy) setter(field string) (reflect.Method, bool) {
+	if s == nil {
+		return reflect.Method{}, false
+	}
+	m, exists := s.setters[field]
+	return m, exists
+}
+
+// fieldType returns the type of the values set to the target field: the argument of its setter if it has one,
+// otherwise the type of the field.
+func (s *rulesStrategy) fieldType(key rulesKey, field string) reflect.Type {
+	if m, exists := s.setter(field); exists {
+		return m.Type.In(1)
+	}
+	return getFieldByName(field, key.target).Type
+}
+
+// mapSetterField maps a target field through its setter: the value from its rule, or from the source field
+// named after the setter, e.g. Name for SetName, is passed to the setter.
+//
+// The setter is not called when the field is ignored or there is no value for it.
+func mapSetterField(key rulesKey, rules RulesSet, source, target reflect.Value, field string, setter reflect.Method, actualS reflect.Value, state *mappingState) error {
+	value := reflect.New(setter.Type.In(1)).Elem()
+	if rule, exists := rules[field]; exists {
+		if rule == nil {
+			return nil
+		}
+		if err := applyRule(source, value, rule, actualS, state); err != nil {
+			return err
+		}
+	} else {
+		sourceValue := source.FieldByName(setter.Name[len("Set"):])
+		if !sourceValue.IsValid() {
+			logTargetFieldWithoutMappingValueInSource(state, key, field)
+			return nil
+		}
+		pType, err := fieldToField(sourceValue, value, state)
+		if err != nil {
+			return err
+		}
+		if pType == incompatibleTypes {
+			logIgnoringMappingForIncompatibleTypes(state, key, field, sourceValue, value)
+			return nil
+		}
+	}
+
+	results := target.Addr().Method(setter.Index).Call([]reflect.Value{value})
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+// upperFirst returns s with its first letter in upper case.
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,185 @@
+package structsconv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type purchaseDto struct {
+	ID    string
+	Name  string
+	Email string
+	Items []string
+}
+
+type purchaseEmail string
+
+type purchase struct {
+	id    string
+	name  string
+	email purchaseEmail
+	items []string
+}
+
+func newPurchase(id string, items ...string) (purchase, error) {
+	if id == "" {
+		return purchase{}, errors.New("empty id")
+	}
+	return purchase{id: id, items: items}, nil
+}
+
+func (p *purchase) SetName(name string) { p.name = strings.ToUpper(name) }
+
+func (p *purchase) SetEmail(email string) error {
+	if !strings.Contains(email, "@") {
+		return errors.New("invalid email")
+	}
+	p.email = purchaseEmail(email)
+	return nil
+}
+
+type purchaseHolderDto struct{ Order purchaseDto }
+
+type purchaseHolder struct{ Order purchase }
+
+func Test_Map_constructor(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source: purchaseDto{},
+		Target: purchase{},
+		Constructor: func(dto purchaseDto, prefix string) (purchase, error) {
+			return newPurchase(dto.ID, append([]string{prefix}, dto.Items...)...)
+		},
+	})
+	m := New(WithLogger(nil), AllowUnexported(false))
+
+	var got purchaseHolder
+	src := purchaseHolderDto{Order: purchaseDto{ID: "o1", Name: "john", Items: []string{"a"}}}
+	if err := m.MapE(&src, &got, "p"); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if got.Order.id != "o1" || strings.Join(got.Order.items, ",") != "p,a" || got.Order.name != "" {
+		t.Errorf("MapE() = %+v, want purchase o1 with items p,a", got.Order)
+	}
+
+	src.Order.ID = ""
+	var me *MappingError
+	if err := m.MapE(&src, &got, "p"); !errors.As(err, &me) || me.Path != "Order" {
+		t.Errorf("MapE() error = %v, want path Order", err)
+	}
+}
+
+func Test_Map_setters(t *testing.T) {
+	withRegistry(t, RulesDefinition{
+		Source:  purchaseDto{},
+		Target:  purchase{},
+		Setters: true,
+		Rules: RulesSet{
+			"email": func(dto purchaseDto) string { return dto.Email + "@example.org" },
+		},
+	})
+	m := New(WithLogger(nil), AllowUnexported(false))
+
+	var got purchase
+	src := purchaseDto{ID: "o1", Name: "john", Email: "john", Items: []string{"a"}}
+	if err := m.MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := "JOHN"; got.name != want {
+		t.Errorf("name = %s, want %s", got.name, want)
+	}
+	if want := purchaseEmail("john@example.org"); got.email != want {
+		t.Errorf("email = %s, want %s", got.email, want)
+	}
+	if got.id != "" || got.items != nil { // no setters, unexported fields are not allowed
+		t.Errorf("MapE() = %+v, want id and items unset", got)
+	}
+}
+
+func Test_Map_setters_error(t *testing.T) {
+	withRegistry(t, RulesDefinition{Source: purchaseDto{}, Target: purchase{}, Setters: true})
+
+	var got purchase
+	src := purchaseDto{Name: "john", Email: "john"}
+	var me *MappingError
+	err := New(WithLogger(nil)).MapE(&src, &got)
+	if !errors.As(err, &me) || me.Path != "email" || me.Err.Error() != "invalid email" {
+		t.Errorf("MapE() error = %v, want invalid email at email", err)
+	}
+}
+
+func Test_registerRules_strategy_panics(t *testing.T) {
+	tests := []struct {
+		name         string
+		definition   RulesDefinition
+		wantContains string
+	}{
+		{
+			name:         "Constructor is not a function,panic expected",
+			definition:   RulesDefinition{Source: purchaseDto{}, Target: purchase{}, Constructor: "newPurchase"},
+			wantContains: "Constructor must be a function",
+		},
+		{
+			name:         "Constructor returns a different type,panic expected",
+			definition:   RulesDefinition{Source: purchaseDto{}, Target: purchase{}, Constructor: func(purchaseDto) *purchase { return nil }},
+			wantContains: "Function 'Constructor' must return type 'structsconv.purchase', currently returns '*structsconv.purchase'",
+		},
+		{
+			name: "Constructor with rules,panic expected",
+			definition: RulesDefinition{
+				Source: purchaseDto{}, Target: purchase{}, Rules: RulesSet{"id": "ID"},
+				Constructor: func(purchaseDto) purchase { return purchase{} },
+			},
+			wantContains: "Constructor cannot be combined with Rules or Setters",
+		},
+		{
+			name:         "Setters without setters,panic expected",
+			definition:   RulesDefinition{Source: purchaseDto{}, Target: purchaseDto{}, Setters: true},
+			wantContains: "Setters is set, but *structsconv.purchaseDto has no setter for its fields",
+		},
+		{
+			name: "Rule returns the field type instead of the setter argument,panic expected",
+			definition: RulesDefinition{
+				Source: purchaseDto{}, Target: purchase{}, Setters: true,
+				Rules: RulesSet{"email": func() purchaseEmail { return "" }},
+			},
+			wantContains: "Function 'email' must return type 'string', currently returns 'structsconv.purchaseEmail'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRegistry(t, RulesDefinition{Source: purchaseHolderDto{}, Target: purchaseHolder{}})
+			var f = func() { RegisterRulesDefinitions(tt.definition) }
+			assertPanic(f, tt.wantContains, t)
+			if _, exists := loadRegistry().strategies[buildKey(tt.definition.Source, tt.definition.Target)]; exists {
+				t.Errorf("strategy registered for an invalid definition")
+			}
+		})
+	}
+}
+
+func Test_Explain_strategies(t *testing.T) {
+	withRegistry(t, RulesDefinition{Source: purchaseDto{}, Target: purchase{}, Setters: true})
+	RegisterRulesDefinitions(RulesDefinition{
+		Source:      purchaseHolderDto{},
+		Target:      purchaseHolder{},
+		Constructor: func(dto purchaseHolderDto) purchaseHolder { return purchaseHolder{} },
+	})
+
+	plan, err := Explain(purchaseDto{}, purchase{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	text := plan.String()
+	for _, want := range []string{"name string: name Name (direct) via SetName", "email structsconv.purchaseEmail: name Email (direct) via SetEmail"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Explain() = %s, want it to contain %q", text, want)
+		}
+	}
+
+	plan, _ = Explain(purchaseHolderDto{}, purchaseHolder{})
+	if want := "structsconv.purchaseHolderDto -> structsconv.purchaseHolder: constructor func(structsconv.purchaseHolderDto) structsconv.purchaseHolder\n"; plan.String() != want {
+		t.Errorf("Explain() = %q, want %q", plan.String(), want)
+	}
+}
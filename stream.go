@@ -0,0 +1,182 @@
+package structsconv
+
+import (
+	"context"
+	"reflect"
+)
+
+// Result is the outcome of mapping an item of a stream, see MapChan.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// MapChan maps the structs received from src with the default Mapper, sending a Result for each of them to the
+// returned channel, in the same order. The channel is closed when src is closed or ctx is done.
+//
+// A failed item does not stop the stream, its Result holds the error, whose path starts with the position of the
+// item in the stream, e.g. "[3].Name". Errors of the source and target types are sent once, before closing.
+func MapChan[S, T any](ctx context.Context, src <-chan S, args ...interface{}) <-chan Result[T] {
+	return MapChanWith[S, T](defaultMapper(), ctx, src, args...)
+}
+
+// MapChanWith works like MapChan, with the Mapper m and its options.
+func MapChanWith[S, T any](m *Mapper, ctx context.Context, src <-chan S, args ...interface{}) <-chan Result[T] {
+	out := make(chan Result[T])
+	go func() {
+		defer close(out)
+		send := func(r Result[T]) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			select {
+			case out <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		im, err := newItemMapper[S, T](ctx, m, args)
+		if err != nil {
+			send(Result[T]{Err: err})
+			return
+		}
+		for i := 0; ; i++ {
+			var s S
+			var ok bool
+			select {
+			case s, ok = <-src:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			t, err := im.mapItem(i, &s)
+			if !send(Result[T]{Value: t, Err: err}) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MapSeq returns an iterator over the structs of seq mapped with the default Mapper, for range-over-func:
+//
+//	for order, err := range structsconv.MapSeq[dto.OrderDto, domain.Order](ctx, cursor.All) { ... }
+//
+// A failed item does not stop the iteration, it is yielded with its error, whose path starts with the position of
+// the item, e.g. "[3].Name". When ctx is done, ctx.Err() is yielded and the iteration stops.
+func MapSeq[S, T any](ctx context.Context, seq func(yield func(S) bool), args ...interface{}) func(yield func(T, error) bool) {
+	return MapSeqWith[S, T](defaultMapper(), ctx, seq, args...)
+}
+
+// MapSeqWith works like MapSeq, with the Mapper m and its options.
+func MapSeqWith[S, T any](m *Mapper, ctx context.Context, seq func(yield func(S) bool), args ...interface{}) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		var zero T
+		im, err := newItemMapper[S, T](ctx, m, args)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		i := 0
+		seq(func(s S) bool {
+			if err := checkContext(ctx); err != nil {
+				yield(zero, err)
+				return false
+			}
+			t, err := im.mapItem(i, &s)
+			i++
+			return yield(t, err)
+		})
+	}
+}
+
+// MapEach maps the structs of src with the default Mapper and calls fn with each of them, in order.
+//
+// The first error stops the iteration and is returned: the mapping errors have a path starting with the index of
+// the item, e.g. "[3].Name", and the errors returned by fn are returned as is.
+func MapEach[S, T any](src []S, fn func(T) error, args ...interface{}) error {
+	return MapEachContextWith(defaultMapper(), context.Background(), src, fn, args...)
+}
+
+// MapEachContext works like MapEach, the iteration is aborted with ctx.Err() when the context is done.
+func MapEachContext[S, T any](ctx context.Context, src []S, fn func(T) error, args ...interface{}) error {
+	return MapEachContextWith(defaultMapper(), ctx, src, fn, args...)
+}
+
+// MapEachWith works like MapEach, with the Mapper m and its options.
+func MapEachWith[S, T any](m *Mapper, src []S, fn func(T) error, args ...interface{}) error {
+	return MapEachContextWith(m, context.Background(), src, fn, args...)
+}
+
+// MapEachContextWith works like MapEachContext, with the Mapper m and its options.
+func MapEachContextWith[S, T any](m *Mapper, ctx context.Context, src []S, fn func(T) error, args ...interface{}) error {
+	im, err := newItemMapper[S, T](ctx, m, args)
+	if err != nil {
+		return err
+	}
+	for i := range src {
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		t, err := im.mapItem(i, &src[i])
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemMapper maps the items of a stream, the state of the mapping is shared by all the items.
+type itemMapper[S, T any] struct {
+	state *mappingState
+}
+
+// newItemMapper checks the source and target types and creates the state shared by the items.
+func newItemMapper[S, T any](ctx context.Context, m *Mapper, args []interface{}) (*itemMapper[S, T], error) {
+	var s S
+	var t T
+	sourceV := reflect.ValueOf(&s)
+	if err := checkRootValuesTypes(sourceV, reflect.ValueOf(&t)); err != nil {
+		return nil, err
+	}
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	state, err := m.newState(ctx, sourceV, args)
+	if err != nil {
+		return nil, err
+	}
+	return &itemMapper[S, T]{state: state}, nil
+}
+
+// mapItem maps the item at position i of the stream, which is the root source of its mapping.
+//
+// Items are independent: the source pointers are only shared within an item.
+func (im *itemMapper[S, T]) mapItem(i int, source *S) (T, error) {
+	var t T
+	state := im.state
+	state.frames = state.frames[:0]
+	state.depth = 0
+	state.order = 0
+	state.pointers = &mappedPointers{pointers: make(map[pointerKey]*mappedPointer)}
+
+	sourceV, targetV := reflect.ValueOf(source), reflect.ValueOf(&t)
+	state.setRootItem(sourceV)
+	state.addPointer(sourceV, targetV)
+	err := state.run(func() error {
+		state.pushIndex(i)
+		err := structToStruct(sourceV.Elem(), targetV.Elem(), sourceV.Elem(), state)
+		state.pop()
+		if err != nil {
+			return wrapIndexError(i, err)
+		}
+		return nil
+	})
+	return t, err
+}
@@ -0,0 +1,177 @@
+package structsconv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_MapChan(t *testing.T) {
+	registerBookRules(t)
+
+	src := make(chan bookDto, 3)
+	src <- bookDto{Title: "t1", Author: "a1"}
+	src <- bookDto{Title: "t2"}
+	src <- bookDto{Title: "t3", Author: "a3"}
+	close(src)
+
+	var got []Result[book]
+	for r := range MapChan[bookDto, book](context.Background(), src, "> ") {
+		got = append(got, r)
+	}
+	if len(got) != 3 {
+		t.Fatalf("MapChan() got %d results, want 3", len(got))
+	}
+	if want := (book{Title: "t1", Label: "> t1 by a1"}); got[0].Err != nil || got[0].Value != want {
+		t.Errorf("MapChan() [0] = %+v, want %+v", got[0], want)
+	}
+	var me *MappingError
+	if !errors.As(got[1].Err, &me) || me.Path != "[1].Label" {
+		t.Errorf("MapChan() [1] error = %v, want path [1].Label", got[1].Err)
+	}
+	if want := (book{Title: "t3", Label: "> t3 by a3"}); got[2].Err != nil || got[2].Value != want {
+		t.Errorf("MapChan() [2] = %+v, want %+v", got[2], want)
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		src := make(chan bookDto) // never sends
+		out := MapChan[bookDto, book](ctx, src)
+		cancel()
+		if _, ok := <-out; ok {
+			t.Errorf("MapChan() sent a result after cancel, want closed channel")
+		}
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		src := make(chan int)
+		r, ok := <-MapChan[int, book](context.Background(), src)
+		if !ok || r.Err == nil {
+			t.Errorf("MapChan() = %+v, want types error", r)
+		}
+	})
+}
+
+func Test_MapSeq(t *testing.T) {
+	registerBookRules(t)
+	dtos := []bookDto{{Title: "t1", Author: "a1"}, {Title: "t2"}, {Title: "t3", Author: "a3"}}
+	seq := func(yield func(bookDto) bool) {
+		for _, d := range dtos {
+			if !yield(d) {
+				return
+			}
+		}
+	}
+
+	var got []book
+	var errs []error
+	MapSeq[bookDto, book](context.Background(), seq, "> ")(func(b book, err error) bool {
+		got = append(got, b)
+		errs = append(errs, err)
+		return true
+	})
+	if len(got) != 3 || errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("MapSeq() = %+v, errors %v", got, errs)
+	}
+	if want := (book{Title: "t3", Label: "> t3 by a3"}); got[2] != want {
+		t.Errorf("MapSeq() [2] = %+v, want %+v", got[2], want)
+	}
+
+	t.Run("break", func(t *testing.T) {
+		n := 0
+		MapSeq[bookDto, book](context.Background(), seq, "> ")(func(book, error) bool {
+			n++
+			return false
+		})
+		if n != 1 {
+			t.Errorf("MapSeq() yielded %d items after break, want 1", n)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var errs []error
+		MapSeq[bookDto, book](ctx, seq)(func(_ book, err error) bool {
+			errs = append(errs, err)
+			return true
+		})
+		if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+			t.Errorf("MapSeq() errors = %v, want [context.Canceled]", errs)
+		}
+	})
+}
+
+func Test_MapEach(t *testing.T) {
+	registerBookRules(t)
+	dtos := []bookDto{{Title: "t1", Author: "a1"}, {Title: "t2", Author: "a2"}}
+
+	var got []book
+	err := MapEach(dtos, func(b book) error {
+		got = append(got, b)
+		return nil
+	}, "> ")
+	if err != nil {
+		t.Fatalf("MapEach() error = %v", err)
+	}
+	want := []book{{Title: "t1", Label: "> t1 by a1"}, {Title: "t2", Label: "> t2 by a2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MapEach() = %+v, want %+v", got, want)
+	}
+
+	t.Run("mapping error", func(t *testing.T) {
+		src := []bookDto{dtos[0], {Title: "t2"}}
+		n := 0
+		err := MapEach(src, func(book) error { n++; return nil }, "> ")
+		var me *MappingError
+		if !errors.As(err, &me) || me.Path != "[1].Label" || n != 1 {
+			t.Errorf("MapEach() error = %v after %d items, want path [1].Label after 1", err, n)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		err := MapEach(dtos, func(book) error { return stop }, "> ")
+		if err != stop {
+			t.Errorf("MapEach() error = %v, want %v", err, stop)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := MapEachContext(ctx, dtos, func(book) error { return nil })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("MapEachContext() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func Test_MapStreams_mapper(t *testing.T) {
+	withRegistry(t)
+	m := New(WithLogger(nil), WithMaxDepth(1))
+	dtos := []teamDto{{Lead: memberDto{Name: "ann", Email: "ann@x.org"}}}
+	seq := func(yield func(teamDto) bool) { yield(dtos[0]) }
+	src := make(chan teamDto, 1)
+	src <- dtos[0]
+	close(src)
+
+	var errs []error
+	errs = append(errs, MapEachWith(m, dtos, func(team) error { return nil }))
+	MapSeqWith[teamDto, team](m, context.Background(), seq)(func(_ team, err error) bool {
+		errs = append(errs, err)
+		return true
+	})
+	for r := range MapChanWith[teamDto, team](m, context.Background(), src) {
+		errs = append(errs, r.Err)
+	}
+	for i, err := range errs {
+		if !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("[%d] error = %v, want %v from the Mapper options", i, err, ErrMaxDepth)
+		}
+	}
+	if len(errs) != 3 {
+		t.Errorf("got %d results, want 3", len(errs))
+	}
+}
@@ -1,19 +1,16 @@
 package structsconv
 
 import (
+	"context"
 	"log"
 	"reflect"
-	"unsafe"
 )
 
-// rulesRegistry mapping rules register.
-var rulesRegistry = make(mapperRulesRegistry)
-
 // RegisterRulesDefinitions it is used to register rule definitions.
 func RegisterRulesDefinitions(definitions ...interface{}) {
 	for _, d := range definitions {
 		r := parseRulesDefinition(d)
-		registerRules(r.Source, r.Target, r.Rules)
+		registerRules(r)
 	}
 }
 
@@ -54,244 +51,378 @@ func getRulesFromSet(valDefinitions reflect.Value) []interface{} {
 }
 
 // registerRules verifies and registers a mapper rules for specific mapping from structure to structure.
-func registerRules(source interface{}, target interface{}, rules RulesSet) {
-	key := buildKey(source, target)
-	_, exists := rulesRegistry[key]
-	if exists {
-		log.Panicf("ERROR: Mapper with rulesKey (%s -> %s) already exists.", key.source, key.target)
+func registerRules(d RulesDefinition) {
+	key := buildKey(d.Source, d.Target)
+	updateRegistry(func(r *registry) {
+		if _, exists := r.rules[key]; exists {
+			log.Panicf("ERROR: Mapper with rulesKey (%s -> %s) already exists.", key.source, key.target)
+		}
+		setRules(r, key, d)
+	})
+}
+
+// setRules verifies the rules of the definition and sets them with the key in the registry r, replacing the current
+// ones. The current rules are kept if the definition is not valid.
+func setRules(r *registry, key rulesKey, d RulesDefinition) {
+	rules := resolveRules(key, d)
+	d.Rules = rules
+	strategy := checkStrategy(key, d)
+	checkStrategyRules(key, rules, strategy)
+	r.rules[key] = rules
+	if strategy != nil {
+		r.strategies[key] = strategy
+	} else {
+		delete(r.strategies, key)
 	}
-	checkMapperRules(key, rules)
-	rulesRegistry[key] = rules
 }
 
 // Map maps the source structure to a destination structure; source and target must be pointers to structs.
 //
-// The value will be mapped into the target structure.
+// The value will be mapped into the target structure. Map panics if the mapping fails, use MapE to get the error instead.
 func Map(source interface{}, target interface{}, args ...interface{}) {
-	sourceV := reflect.ValueOf(source)
-	targetV := reflect.ValueOf(target)
-	if err := checkRootValuesTypes(sourceV, targetV); err != nil { // check if the source and target are pointers
-		log.Panicf("ERROR: %s", err)
+	defaultMapper().Map(source, target, args...)
+}
+
+// MapE maps the source structure to a destination structure; source and target must be pointers to structs.
+//
+// The value will be mapped into the target structure. Any error returned by a rule function aborts the mapping
+// and is returned wrapped in a *MappingError with the path of the target field.
+func MapE(source interface{}, target interface{}, args ...interface{}) error {
+	return defaultMapper().MapE(source, target, args...)
+}
+
+// MapContext works like MapE, but the given context is passed to the rule functions that request a context.Context,
+// and the mapping is aborted with ctx.Err() when the context is done.
+func MapContext(ctx context.Context, source interface{}, target interface{}, args ...interface{}) error {
+	return defaultMapper().MapContext(ctx, source, target, args...)
+}
+
+// MapAny works like MapE, but source and target can also be pointers to slices, arrays or maps of structs,
+// which are mapped item by item. Every item is the root source of its own mapping.
+func MapAny(source interface{}, target interface{}, args ...interface{}) error {
+	return defaultMapper().MapAny(source, target, args...)
+}
+
+// MapAnyContext works like MapAny, with the context handling of MapContext.
+func MapAnyContext(ctx context.Context, source interface{}, target interface{}, args ...interface{}) error {
+	return defaultMapper().MapAnyContext(ctx, source, target, args...)
+}
+
+// checkContext returns ctx.Err() if the context is done.
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
 	}
-	args = append([]interface{}{sourceV.Interface()}, args...)
-	args = append(args, sourceV.Elem().Interface())
-	structToStruct(sourceV.Elem(), targetV.Elem(), sourceV.Elem().Interface(), groupArgs(args))
 }
 
 // groupArgs groups the arguments by their type.
 func groupArgs(args []interface{}) groupedArgs {
-	g := make(groupedArgs)
+	g := make(groupedArgs, 0, len(args))
 	for _, v := range args {
 		t := reflect.TypeOf(v)
-		g[t] = append(g[t], v)
+		if i := g.find(t); i >= 0 {
+			g[i].values = append(g[i].values, reflect.ValueOf(v))
+			continue
+		}
+		g = append(g, argsGroup{t: t, values: []reflect.Value{reflect.ValueOf(v)}})
 	}
 	return g
 }
 
 // structToStruct maps the source struct to the target struct
-func structToStruct(source, target reflect.Value, actualS interface{}, args groupedArgs) {
+func structToStruct(source, target, actualS reflect.Value, state *mappingState) error {
 	key := rulesKey{source.Type(), target.Type()}
-	rules := rulesRegistry[key]
+	rules := state.registry.rules[key]
 	targetType := target.Type()
 
+	if err := state.enterStruct(); err != nil {
+		return err
+	}
+	defer state.leaveStruct()
+
+	strategy := state.registry.strategies[key]
+	if err := strategy.beforeMap(target, actualS, state); err != nil {
+		return err
+	}
+	if strategy != nil && strategy.constructor.IsValid() {
+		if err := callFunc(target, strategy.constructor, actualS, state); err != nil {
+			return err
+		}
+		return strategy.afterMap(target, actualS, state)
+	}
+
 	for i := 0; i < target.NumField(); i++ {
 		targetFieldName := targetType.Field(i).Name
-		targetValue := target.Field(i)
-
-		// if there is a rule for this field, use it
-		if mapper, exists := rules[targetFieldName]; exists {
-			// if the rule is not nil (is not ignorable) apply rule
-			if mapper != nil {
-				applyRule(source, targetValue, mapper, actualS, args)
-			}
-			continue
+		state.pushField(targetFieldName, actualS)
+		var err error
+		if setter, exists := strategy.setter(targetFieldName); exists && target.CanAddr() {
+			err = mapSetterField(key, rules, source, target, targetFieldName, setter, actualS, state)
+		} else if targetType.Field(i).IsExported() || state.unexportedAllowed() {
+			err = mapTargetField(key, rules, source, target.Field(i), targetFieldName, actualS, state)
+		} else {
+			err = skipUnexportedField(key, rules, targetFieldName, state)
 		}
+		state.pop()
+		if err != nil {
+			return wrapFieldError(targetFieldName, err)
+		}
+	}
+	return strategy.afterMap(target, actualS, state)
+}
 
-		// field-to-field mapping source field to target field by target field name
-		sourceValue := source.FieldByName(targetFieldName)
-		if sourceValue.IsValid() {
-			if pType := fieldToField(sourceValue, targetValue, args); pType == incompatibleTypes {
-				logIgnoringMappingForIncompatibleTypes(key, targetFieldName, sourceValue, targetValue)
-			}
-			continue
+// mapTargetField maps a single target field, using its rule if there is one, otherwise the source field with the same name.
+func mapTargetField(key rulesKey, rules RulesSet, source, targetValue reflect.Value, targetFieldName string, actualS reflect.Value, state *mappingState) error {
+	// if there is a rule for this field, use it
+	if mapper, exists := rules[targetFieldName]; exists {
+		// if the rule is not nil (is not ignorable) apply rule
+		if mapper != nil {
+			return applyRule(source, targetValue, mapper, actualS, state)
 		}
+		return nil
+	}
 
-		// A target field without mapping value in source
-		logTargetFieldWithoutMappingValueInSource(key, targetFieldName)
+	// field-to-field mapping source field to target field by target field name
+	sourceValue := source.FieldByName(targetFieldName)
+	if sourceValue.IsValid() && !sourceValue.CanInterface() && !state.unexportedAllowed() {
+		logSkippedUnexported(state, key, targetFieldName) // promoted from an unexported embedded struct
+		return nil
 	}
+	if sourceValue.IsValid() {
+		pType, err := fieldToField(sourceValue, targetValue, state)
+		if err != nil {
+			return err
+		}
+		if pType == incompatibleTypes {
+			logIgnoringMappingForIncompatibleTypes(state, key, targetFieldName, sourceValue, targetValue)
+			return nil
+		}
+		logFieldMappedByName(state, key, targetFieldName)
+		return nil
+	}
+
+	// A target field without mapping value in source
+	logTargetFieldWithoutMappingValueInSource(state, key, targetFieldName)
+	return nil
 }
 
 // applyRule processes a rule for a target field.
-func applyRule(source, targetValue reflect.Value, mapper, actualS interface{}, args groupedArgs) {
+func applyRule(source, targetValue reflect.Value, mapper interface{}, actualS reflect.Value, state *mappingState) error {
+	if rule, ok := mapper.(SwitchRule); ok { // mapper chooses the target type by a source field
+		return applySwitch(source, targetValue, rule, state)
+	}
 	switch mapperValue := reflect.ValueOf(mapper); mapperValue.Kind() {
 	case reflect.String: // mapper has the name of the source field
-		fieldToField(source.FieldByName(mapper.(string)), targetValue, args)
+		sourceValue := source.FieldByName(mapper.(string))
+		if err := checkUnexportedSource(sourceValue, mapper.(string), state); err != nil {
+			return err
+		}
+		_, err := fieldToField(sourceValue, targetValue, state)
+		return err
 	default: // mapper is a function
-		callFunc(targetValue, mapperValue, actualS, args)
+		return callFunc(targetValue, mapperValue, actualS, state)
 	}
 }
 
-// callFunc calls a function with the given arguments
-func callFunc(targetValue, mapperValue reflect.Value, actualS interface{}, args groupedArgs) {
+// callFunc calls a function with the given arguments.
+//
+// If the function returns a second value of type error and it is not nil, the error is returned and the target is not set.
+func callFunc(targetValue, mapperValue, actualS reflect.Value, state *mappingState) error {
 	method := mapperValue.Type()
+	var results []reflect.Value
 	if method.NumIn() == 0 {
-		mappingDirectMapping(mapperValue.Call([]reflect.Value{})[0], targetValue)
+		results = mapperValue.Call(nil)
 	} else {
-		params := getMethodParams(method, args, actualS)
-		mappingDirectMapping(mapperValue.Call(params)[0], targetValue)
-	}
-}
-
-// getMethodParams gets the arguments of the method based on its input parameters
-func getMethodParams(method reflect.Type, args groupedArgs, current interface{}) []reflect.Value {
-	params := make([]reflect.Value, method.NumIn())
-	argsCounter := make(map[reflect.Type]int)
-	cType := reflect.TypeOf(current)
-	var cFlag bool
-	for i := 0; i < method.NumIn(); i++ {
-		mType := method.In(i)
-
-		// Current struct
-		if !cFlag && cType == mType {
-			params[i] = reflect.ValueOf(current)
-			cFlag = true
-			continue
-		}
-
-		// Arguments
-		if l, exists := args[mType]; exists && argsCounter[mType] < len(l) {
-			c, _ := argsCounter[mType]
-			params[i] = reflect.ValueOf(l[c])
-			argsCounter[mType]++
-			continue
+		params, err := getMethodParams(method, state, actualS, reflect.Value{})
+		if err != nil {
+			return err
 		}
-
-		// Zero value
-		logPassingZeroValue(method, mType, i+1)
-		params[i] = reflect.Zero(mType)
+		results = mapperValue.Call(params)
+		clear(params) // the buffer is reused, do not retain the arguments
+	}
+	if len(results) == 2 && !results[1].IsNil() {
+		return results[1].Interface().(error)
 	}
-	return params
+	mappingDirectMapping(results[0], targetValue, state)
+	return nil
 }
 
 // fieldToField field to field mapping orchestration
-func fieldToField(sourceValue, targetValue reflect.Value, args groupedArgs) processingResultType {
-	mappingType := getMappingType(sourceValue, targetValue)
+func fieldToField(sourceValue, targetValue reflect.Value, state *mappingState) (processingResultType, error) {
+	var err error
+	mappingType := getMappingType(state.registry, sourceValue, targetValue)
 	switch mappingType {
 	case structsMapping:
-		cMappingStructLogic(sourceValue, targetValue, args)
+		err = cMappingStructLogic(sourceValue, targetValue, state)
 	case slicesMapping:
-		cMappingSliceLogic(sourceValue, targetValue, args)
+		err = cMappingSliceLogic(sourceValue, targetValue, state)
 	case mapsMapping:
-		cMappingMapLogic(sourceValue, targetValue, args)
+		err = cMappingMapLogic(sourceValue, targetValue, state)
 	case arraysMapping:
-		cMappingArrayLogic(sourceValue, targetValue, args)
+		err = cMappingArrayLogic(sourceValue, targetValue, state)
 	case directMapping:
-		mappingDirectMapping(sourceValue, targetValue)
+		if state.mapper.deepCopy && sourceValue.Type() == targetValue.Type() {
+			err = deepCopyValue(sourceValue, targetValue, state)
+		} else {
+			mappingDirectMapping(sourceValue, targetValue, state)
+		}
 	case ptrMapping:
-		mappingPtrMapping(sourceValue, targetValue, args)
-	default:
-		return mappingType
+		err = mappingPtrMapping(sourceValue, targetValue, state)
+	case interfaceMapping:
+		err = cMappingInterfaceLogic(sourceValue, targetValue, state)
 	}
-	return mappingType
+	return mappingType, err
 }
 
 // cMappingStructLogic is used to be called as a goroutine and map the source field to the destination field
-func cMappingStructLogic(source, target reflect.Value, args groupedArgs) {
+func cMappingStructLogic(source, target reflect.Value, state *mappingState) error {
 	if !source.CanInterface() {
 		source = getUnexportedField(source)
 	}
-	structToStruct(source, target, source.Interface(), args)
+	return structToStruct(source, target, source, state)
 }
 
 // cMappingMapLogic is used to be called as a goroutine and maps the structures of the source map to the destination map
-func cMappingMapLogic(sourceValue, targetValue reflect.Value, args groupedArgs) {
+func cMappingMapLogic(sourceValue, targetValue reflect.Value, state *mappingState) error {
 	if !targetValue.CanInterface() {
-		log.Printf(
-			"WARNING: Operations on map type fields that are not exported are not supported. Operation ignored. Target = %s\n",
-			targetValue.Type().String(),
+		logUnsupportedUnexported(state,
+			"Operations on map type fields that are not exported are not supported. Operation ignored.",
+			"target", targetValue.Type().String(),
 		)
-		return
+		return nil
 	}
 
-	itemType := targetValue.Type().Elem()
-	mappingDirectMapping(reflect.MakeMap(targetValue.Type()), targetValue)
+	// the item is reused, SetMapIndex stores a copy of it
+	item := reflect.New(targetValue.Type().Elem()).Elem()
+	mappingDirectMapping(reflect.MakeMap(targetValue.Type()), targetValue, state)
 	for _, key := range sourceValue.MapKeys() {
-		item := reflect.New(itemType)
+		if err := checkContext(state.ctx); err != nil {
+			return err
+		}
+		item.SetZero()
 		sourceItem := sourceValue.MapIndex(key)
 		if !sourceItem.CanInterface() {
-			log.Printf(
-				"WARNING: Operations on MAP type fields that are not exported are not supported. Operation ignored. Source Item = %s\n",
-				sourceItem.Type().String(),
+			logUnsupportedUnexported(state,
+				"Operations on MAP type fields that are not exported are not supported. Operation ignored.",
+				"sourceItem", sourceItem.Type().String(),
 			)
-			return
+			return nil
 		}
-		structToStruct(sourceItem, item.Elem(), sourceItem.Interface(), args)
-		targetValue.SetMapIndex(key, item.Elem())
+		state.setRootItem(sourceItem)
+		state.pushKey(key)
+		err := structToStruct(sourceItem, item, sourceItem, state)
+		state.pop()
+		if err != nil {
+			return wrapKeyError(key, err)
+		}
+		targetValue.SetMapIndex(key, item)
 	}
+	return nil
 }
 
 // cMappingArrayLogic is used to be called as a goroutine and maps the structures of the source array to the destination array
-func cMappingArrayLogic(sourceValue, targetValue reflect.Value, args groupedArgs) {
+func cMappingArrayLogic(sourceValue, targetValue reflect.Value, state *mappingState) error {
 	if !targetValue.CanInterface() {
 		targetValue = getUnexportedField(targetValue)
 	}
 	itemType := targetValue.Type().Elem()
-	for i := 0; i < targetValue.Cap(); i++ {
+	n := targetValue.Len()
+	if sourceValue.Len() < n {
+		n = sourceValue.Len()
+	}
+	return state.forEachItem(n, func(i int, state *mappingState) error {
 		item := reflect.New(itemType)
 		sourceItem := sourceValue.Index(i)
 		if !sourceItem.CanInterface() {
 			sourceItem = getUnexportedField(sourceItem)
 		}
-		structToStruct(sourceItem, item.Elem(), sourceItem.Interface(), args)
+		state.setRootItem(sourceItem)
+		state.pushIndex(i)
+		err := structToStruct(sourceItem, item.Elem(), sourceItem, state)
+		state.pop()
+		if err != nil {
+			return wrapIndexError(i, err)
+		}
 		targetValue.Index(i).Set(item.Elem())
-	}
+		return nil
+	})
 }
 
 // cMappingSliceLogic is used to be called as a goroutine and maps the structures of the source slice to the destination slice
-func cMappingSliceLogic(sourceValue, targetValue reflect.Value, args groupedArgs) {
+func cMappingSliceLogic(sourceValue, targetValue reflect.Value, state *mappingState) error {
 	if !targetValue.CanInterface() {
 		targetValue = getUnexportedField(targetValue)
 	}
-	itemType := targetValue.Type().Elem()
-	for i := 0; i < sourceValue.Len(); i++ {
-		item := reflect.New(itemType)
+	items := reflect.MakeSlice(targetValue.Type(), sourceValue.Len(), sourceValue.Len())
+	err := state.forEachItem(sourceValue.Len(), func(i int, state *mappingState) error {
+		item := items.Index(i)
 		sourceItem := sourceValue.Index(i)
 		if !sourceItem.CanInterface() {
 			sourceItem = getUnexportedField(sourceItem)
 		}
 
-		if itemType.Kind() == reflect.Ptr {
-			mappingPtrMapping(sourceItem, item.Elem(), args)
-		} else if sourceItem.Kind() == reflect.Ptr {
-			structToStruct(sourceItem.Elem(), item.Elem(), sourceItem.Interface(), args)
-		} else {
-			structToStruct(sourceItem, item.Elem(), sourceItem.Interface(), args)
+		var err error
+		state.setRootItem(sourceItem)
+		state.pushIndex(i)
+		switch {
+		case sourceItem.Kind() == reflect.Ptr && sourceItem.IsNil():
+			// nil items are mapped to zero values
+		case item.Kind() == reflect.Interface:
+			err = cMappingInterfaceLogic(sourceItem, item, state)
+		case item.Kind() == reflect.Ptr:
+			err = mappingPtrMapping(sourceItem, item, state)
+		case sourceItem.Kind() == reflect.Ptr:
+			err = structToStruct(sourceItem.Elem(), item, sourceItem, state)
+		default:
+			err = structToStruct(sourceItem, item, sourceItem, state)
 		}
-		mappingDirectMapping(reflect.Append(targetValue, item.Elem()), targetValue)
+		state.pop()
+		if err != nil {
+			return wrapIndexError(i, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	mappingDirectMapping(reflect.AppendSlice(targetValue, items), targetValue, state)
+	return nil
 }
 
 // mappingPtrMapping is used to map ptr types
-func mappingPtrMapping(sourceValue, targetValue reflect.Value, args groupedArgs) {
+func mappingPtrMapping(sourceValue, targetValue reflect.Value, state *mappingState) error {
+	if !targetValue.CanInterface() && targetValue.CanAddr() {
+		targetValue = getUnexportedField(targetValue)
+	}
+	var err error
 	switch {
 	case sourceValue.Kind() == reflect.Ptr && targetValue.Kind() != reflect.Ptr: // source is a pointer and target is not a pointer
-		fieldToField(sourceValue.Elem(), targetValue, args)
+		_, err = fieldToField(sourceValue.Elem(), targetValue, state)
 	case sourceValue.Kind() != reflect.Ptr && targetValue.Kind() == reflect.Ptr: // source is not a pointer and target is a pointer
 		nv := reflect.New(targetValue.Type().Elem())
 		targetValue.Set(nv)
-		fieldToField(sourceValue, targetValue.Elem(), args)
+		_, err = fieldToField(sourceValue, targetValue.Elem(), state)
 	default: // both are pointers
-		nv := reflect.New(targetValue.Type().Elem())
-		targetValue.Set(nv)
-		fieldToField(sourceValue.Elem(), targetValue.Elem(), args)
+		err = mapPointee(sourceValue, targetValue, state, func(s, t reflect.Value) error {
+			_, err := fieldToField(s, t, state)
+			return err
+		})
 	}
+	return err
 }
 
 // mappingDirectMapping is used to map direct types
-func mappingDirectMapping(s, t reflect.Value) {
+func mappingDirectMapping(s, t reflect.Value, state *mappingState) {
 	switch {
 	case s.CanInterface() && t.CanInterface():
 		t.Set(s)
+	case !state.unexportedAllowed():
+		logUnsupportedUnexported(state,
+			"Operations on unexported fields are not allowed by the Mapper. Operation ignored.",
+			"source", s.Type().String(), "target", t.Type().String(),
+		)
 	case !s.CanInterface() && s.CanAddr() && !t.CanInterface() && t.CanAddr():
 		s := getUnexportedField(s)
 		setUnexportedField(t, s)
@@ -301,19 +432,9 @@ func mappingDirectMapping(s, t reflect.Value) {
 		s := getUnexportedField(s)
 		t.Set(s)
 	default:
-		log.Printf(
-			"WARNING: Operations on MAP type fields that are not exported are not supported. Operation ignored. Source = %s, Target = %s\n",
-			s, t,
+		logUnsupportedUnexported(state,
+			"Operations on MAP type fields that are not exported are not supported. Operation ignored.",
+			"source", s.Type().String(), "target", t.Type().String(),
 		)
 	}
 }
-
-func getUnexportedField(field reflect.Value) reflect.Value {
-	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
-}
-
-func setUnexportedField(field, value reflect.Value) {
-	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).
-		Elem().
-		Set(value)
-}
@@ -1,6 +1,8 @@
 package structsconv
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -230,7 +232,7 @@ func Test_Map_nested_ptr_struct_case5(t *testing.T) {
 	}
 
 	var rules = RulesSet{"Nested1": "Nested"}
-	RegisterRulesDefinitions(RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
+	withRegistry(t, RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
 
 	Map(o, d)
 
@@ -269,7 +271,7 @@ func Test_Map_nested_ptr_struct_case6(t *testing.T) {
 	}
 
 	var rules = RulesSet{"Nested1": "Nested"}
-	RegisterRulesDefinitions(RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
+	withRegistry(t, RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
 
 	Map(o, d)
 
@@ -308,42 +310,7 @@ func Test_Map_nested_ptr_struct_case7(t *testing.T) {
 	}
 
 	var rules = RulesSet{"Nested1": "Nested"}
-	RegisterRulesDefinitions(RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
-
-	Map(o, d)
-
-	if !reflect.DeepEqual(d, want) {
-		t.Errorf("Map(%v, %v) = %v, want %v", o, d, d, want)
-	}
-}
-
-// struct unexported -> ptr struct exported: with rule
-func Test_Map_nested_ptr_struct_case8(t *testing.T) {
-	type nestedSource struct{ field string }
-	type source struct {
-		nested nestedSource
-	}
-
-	type nestedTarget struct{ Field string }
-	type target struct {
-		Nested *nestedTarget
-	}
-
-	o := &source{
-		nested: nestedSource{field: "nested"},
-	}
-
-	d := &target{}
-
-	want := &target{
-		Nested: &nestedTarget{Field: "nested"},
-	}
-
-	var rootRules = RulesSet{"Nested": "nested"}
-	RegisterRulesDefinitions(RulesDefinition{Source: source{}, Target: target{}, Rules: rootRules})
-
-	var nestedRules = RulesSet{"Field": "field"}
-	RegisterRulesDefinitions(RulesDefinition{Source: nestedSource{}, Target: nestedTarget{}, Rules: nestedRules})
+	withRegistry(t, RulesDefinition{Source: source{}, Target: target{}, Rules: rules})
 
 	Map(o, d)
 
@@ -471,650 +438,6 @@ func Test_Map_nested_struct(t *testing.T) {
 	}
 }
 
-func Test_Map_pkg_visibility(t *testing.T) {
-	type args struct {
-		source *TestSource
-		target *TestTarget
-		rules  RulesSet
-		args   []interface{}
-	}
-	var test = []struct {
-		name string
-		args args
-		want TestTarget
-	}{
-		{
-			name: "Mapping structs,unexportedField->unexportedField,exportedField->exportedField",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "valueS1",
-					fieldS2:              314,
-					fieldUnexportedEqual: 314,
-					FieldExportedEqual:   598,
-					singleListFieldS:     []string{"valueS1", "valueS2"},
-				},
-				target: &TestTarget{},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "",
-				fieldT3:              0,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 314,
-				FieldExportedEqual:   598,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,unexportedField->exportedField,exportedField->unexportedField",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "valueS1",
-					fieldS2:              314,
-					fieldUnexportedEqual: 314,
-					FieldExportedEqual:   598,
-					singleListFieldS:     []string{"valueS1", "valueS2"},
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldUnexportedEqual": "FieldExportedEqual",
-					"FieldExportedEqual":   "fieldUnexportedEqual",
-					"singleListFieldT":     "singleListFieldS",
-				},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "",
-				fieldT3:              0,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 598,
-				FieldExportedEqual:   314,
-				singleListFieldT:     []string{"valueS1", "valueS2"},
-			},
-		},
-	}
-
-	for _, tt := range test {
-		t.Run(tt.name, func(t *testing.T) {
-			rulesRegistry = make(mapperRulesRegistry)
-			if tt.args.rules != nil {
-				RegisterRulesDefinitions(RulesDefinition{
-					*tt.args.source,
-					*tt.args.target,
-					tt.args.rules,
-				})
-			}
-			if tt.args.args != nil {
-				Map(tt.args.source, tt.args.target, tt.args.args...)
-			} else {
-				Map(tt.args.source, tt.args.target)
-			}
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
-	}
-}
-
-func Test_Map_pkg_func_and_args(t *testing.T) {
-	type args struct {
-		source *TestSource
-		target *TestTarget
-		rules  RulesSet
-		args   []interface{}
-	}
-	var tests = []struct {
-		name string
-		args args
-		want TestTarget
-	}{
-		{
-			name: "Mapping structs,constant",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "",
-					fieldS2:              0,
-					fieldUnexportedEqual: 0,
-					FieldExportedEqual:   0,
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT3": func() int { return 596 },
-				},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "",
-				fieldT3:              596,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,1 simple arg",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "",
-					fieldS2:              0,
-					fieldUnexportedEqual: 0,
-					FieldExportedEqual:   0,
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT3": func(i int) int { return i },
-				},
-				args: []interface{}{3454},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "",
-				fieldT3:              3454,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,2 simple args",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "",
-					fieldS2:              0,
-					fieldUnexportedEqual: 0,
-					FieldExportedEqual:   0,
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT2": func(s string) string { return s },
-					"fieldT3": func(i int) int { return i },
-				},
-				args: []interface{}{3454, "hello"},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "hello",
-				fieldT3:              3454,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,2 simple args,actual source struct",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "qwerty",
-					fieldS2:              0,
-					fieldUnexportedEqual: 0,
-					FieldExportedEqual:   0,
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT1": func(s TestSource) string { return s.fieldS1 },
-					"fieldT2": func(s string) string { return s },
-					"fieldT3": func(i int) int { return i },
-				},
-				args: []interface{}{3454, "hello"},
-			},
-			want: TestTarget{
-				fieldT1:              "qwerty",
-				fieldT2:              "hello",
-				fieldT3:              3454,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,2 simple args,pointer to actual source struct",
-			args: args{
-				source: &TestSource{
-					fieldS1:              "qwerty",
-					fieldS2:              0,
-					fieldUnexportedEqual: 0,
-					FieldExportedEqual:   0,
-				},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT1": func(s TestSource) string { return s.fieldS1 },
-					"fieldT2": func(s string) string { return s },
-					"fieldT3": func(i int) int { return i },
-				},
-				args: []interface{}{3454, "hello"},
-			},
-			want: TestTarget{
-				fieldT1:              "qwerty",
-				fieldT2:              "hello",
-				fieldT3:              3454,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-		{
-			name: "Mapping structs,zero value",
-			args: args{
-				source: &TestSource{},
-				target: &TestTarget{},
-				rules: RulesSet{
-					"fieldT2": func(s string) string { return s },
-				},
-				args: []interface{}{},
-			},
-			want: TestTarget{
-				fieldT1:              "",
-				fieldT2:              "",
-				fieldT3:              0,
-				ignorableTargetField: "",
-				fieldUnexportedEqual: 0,
-				FieldExportedEqual:   0,
-				singleListFieldT:     []string(nil),
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					*tt.args.source,
-					*tt.args.target,
-					tt.args.rules,
-				})
-			}
-			if tt.args.args != nil {
-				Map(tt.args.source, tt.args.target, tt.args.args...)
-			} else {
-				Map(tt.args.source, tt.args.target)
-			}
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
-	}
-}
-
-func Test_Map_simple_slices(t *testing.T) {
-	type source struct {
-		vSliceV1 []int
-		vSliceV2 []int
-	}
-	type target struct {
-		vSliceV1 []string
-		vSliceV2 []int
-	}
-
-	type args struct {
-		source *source
-		target *target
-		rules  RulesSet
-	}
-	type test struct {
-		name string
-		args args
-		want target
-	}
-	tests := []test{
-		{
-			name: "Source slice with different type than destination",
-			args: args{
-				source: &source{vSliceV1: []int{1, 2, 4}},
-				target: &target{vSliceV1: []string(nil)},
-			},
-			want: target{
-				vSliceV1: []string(nil),
-				vSliceV2: []int(nil),
-			},
-		},
-		{
-			name: "Source slice with same type as the destination",
-			args: args{
-				source: &source{vSliceV2: []int{0, 1, 1, 2, 3, 5}},
-				target: &target{vSliceV1: []string(nil)},
-			},
-			want: target{
-				vSliceV1: []string(nil),
-				vSliceV2: []int{0, 1, 1, 2, 3, 5},
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
-	}
-}
-
-func Test_Map_complex_slices(t *testing.T) {
-	type source struct {
-		field1 string
-		field2 int
-	}
-	type target struct {
-		field1 string
-		field2 int
-	}
-
-	type complexSourceSlice struct{ complexSlice []source }
-	type complexTargetSlice struct{ complexSlice []target }
-
-	type args struct {
-		source *complexSourceSlice
-		target *complexTargetSlice
-		rules  RulesSet
-	}
-	type test struct {
-		name string
-		args args
-		want complexTargetSlice
-	}
-	tests := []test{
-		{
-			name: "Source slice with different type than destination",
-			args: args{
-				source: &complexSourceSlice{
-					complexSlice: []source{
-						{
-							field1: "i1",
-							field2: 1,
-						},
-						{
-							field1: "i2",
-							field2: 2,
-						},
-					},
-				},
-				target: &complexTargetSlice{},
-			},
-			want: complexTargetSlice{
-				complexSlice: []target{
-					{
-						field1: "i1",
-						field2: 1,
-					},
-					{
-						field1: "i2",
-						field2: 2,
-					},
-				},
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-
-			for _, w := range tt.want.complexSlice {
-				var exists bool
-				for _, m := range tt.args.target.complexSlice {
-					if reflect.DeepEqual(w, m) {
-						exists = true
-						break
-					}
-				}
-				if !exists {
-					t.Errorf("%#v is not present in %#v", w, tt.args.target.complexSlice)
-				}
-			}
-		})
-	}
-}
-
-func Test_Map_simple_array(t *testing.T) {
-	type source struct {
-		vSliceV1 [5]int
-		vSliceV2 [5]int
-	}
-	type target struct {
-		vSliceV1 [5]string
-		vSliceV2 [5]int
-	}
-
-	type args struct {
-		source *source
-		target *target
-		rules  RulesSet
-	}
-	type test struct {
-		name string
-		args args
-		want target
-	}
-	tests := []test{
-		{
-			name: "Source array with different type than destination",
-			args: args{
-				source: &source{vSliceV1: [5]int{1, 2, 4}},
-				target: &target{},
-			},
-			want: target{
-				vSliceV1: [5]string{},
-				vSliceV2: [5]int{},
-			},
-		},
-		{
-			name: "Source array with same type as the destination",
-			args: args{
-				source: &source{vSliceV2: [5]int{0, 1, 1, 2, 3}},
-				target: &target{},
-			},
-			want: target{
-				vSliceV1: [5]string{},
-				vSliceV2: [5]int{0, 1, 1, 2, 3},
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
-	}
-}
-
-func Test_Map_complex_array(t *testing.T) {
-	type source struct {
-		field1 string
-		field2 int
-	}
-	type target struct {
-		field1 string
-		field2 int
-	}
-
-	type complexSourceSlice struct{ complexSlice [5]source }
-	type complexTargetSlice struct{ complexSlice [5]target }
-
-	type args struct {
-		source *complexSourceSlice
-		target *complexTargetSlice
-		rules  RulesSet
-	}
-	type test struct {
-		name string
-		args args
-		want complexTargetSlice
-	}
-	tests := []test{
-		{
-			name: "Source slice with different type than destination",
-			args: args{
-				source: &complexSourceSlice{
-					complexSlice: [5]source{
-						{
-							field1: "i1",
-							field2: 1,
-						},
-						{
-							field1: "i2",
-							field2: 2,
-						},
-					},
-				},
-				target: &complexTargetSlice{},
-			},
-			want: complexTargetSlice{
-				complexSlice: [5]target{
-					{
-						field1: "i1",
-						field2: 1,
-					},
-					{
-						field1: "i2",
-						field2: 2,
-					},
-				},
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-
-			for _, w := range tt.want.complexSlice {
-				var exists bool
-				for _, m := range tt.args.target.complexSlice {
-					if reflect.DeepEqual(w, m) {
-						exists = true
-						break
-					}
-				}
-				if !exists {
-					t.Errorf("%#v is not present in %#v", w, tt.args.target.complexSlice)
-				}
-			}
-		})
-	}
-}
-
-func Test_Map_simple_map(t *testing.T) {
-	type source struct {
-		vSliceV1 map[string]int
-		vSliceV2 map[string]int
-	}
-	type target struct {
-		vSliceV1 map[int]int
-		vSliceV2 map[string]int
-	}
-
-	type args struct {
-		source *source
-		target *target
-		rules  RulesSet
-	}
-	type test struct {
-		name string
-		args args
-		want target
-	}
-	tests := []test{
-		{
-			name: "Source map with different type than destination",
-			args: args{
-				source: &source{
-					vSliceV1: map[string]int{
-						"one": 1,
-						"two": 2,
-					},
-				},
-				target: &target{},
-			},
-			want: target{
-				vSliceV1: map[int]int(nil),
-				vSliceV2: map[string]int(nil),
-			},
-		},
-		{
-			name: "Source array with same type as the destination",
-			args: args{
-				source: &source{
-					vSliceV1: map[string]int{
-						"one": 1,
-						"two": 2,
-					},
-					vSliceV2: map[string]int{
-						"one": 1,
-						"two": 2,
-					},
-				},
-				target: &target{},
-			},
-			want: target{
-				vSliceV1: map[int]int(nil),
-				vSliceV2: map[string]int{
-					"one": 1,
-					"two": 2,
-				},
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
-	}
-}
-
 func Test_Map_complex_map(t *testing.T) {
 	type source struct {
 		Field1 string
@@ -1173,11 +496,10 @@ func Test_Map_complex_map(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
+				withRegistry(t, RulesDefinition{
+					Source: tt.args.source,
+					Target: tt.args.target,
+					Rules:  tt.args.rules,
 				})
 			}
 			Map(tt.args.source, tt.args.target)
@@ -1246,11 +568,10 @@ func Test_Map_complex_map_unexported_case_1(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
+				withRegistry(t, RulesDefinition{
+					Source: tt.args.source,
+					Target: tt.args.target,
+					Rules:  tt.args.rules,
 				})
 			}
 			Map(tt.args.source, tt.args.target)
@@ -1310,11 +631,10 @@ func Test_Map_complex_map_unexported_case_2(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					tt.args.source,
-					tt.args.target,
-					tt.args.rules,
+				withRegistry(t, RulesDefinition{
+					Source: tt.args.source,
+					Target: tt.args.target,
+					Rules:  tt.args.rules,
 				})
 			}
 			Map(tt.args.source, tt.args.target)
@@ -1325,69 +645,166 @@ func Test_Map_complex_map_unexported_case_2(t *testing.T) {
 	}
 }
 
-func Test_Map_complex_map_unexported_case_3(t *testing.T) {
+func Test_MapE_rule_error(t *testing.T) {
+	type addressSource struct{ Zip string }
+	type addressTarget struct{ Zip int }
 	type source struct {
-		field1 string
-		field2 int
+		Name      string
+		Addresses []addressSource
 	}
 	type target struct {
-		field1 string
-		field2 int
+		Name      string
+		Addresses []addressTarget
+	}
+
+	errParse := errors.New("invalid zip")
+	withRegistry(t, RulesDefinition{
+		Source: addressSource{},
+		Target: addressTarget{},
+		Rules: RulesSet{
+			"Zip": func(a addressSource) (int, error) {
+				if a.Zip == "" {
+					return 0, errParse
+				}
+				return len(a.Zip), nil
+			},
+		},
+	})
+
+	o := &source{Name: "name", Addresses: []addressSource{{Zip: "10001"}, {Zip: ""}}}
+	d := &target{}
+
+	err := MapE(o, d)
+	if !errors.Is(err, errParse) {
+		t.Fatalf("MapE() error = %v, want %v", err, errParse)
 	}
-	type complexSourceMap struct{ unExComplexMap map[string]source }
-	type complexTargetMap struct{ ExComplexMap map[string]target }
 
-	type args struct {
-		source *complexSourceMap
-		target *complexTargetMap
-		rules  RulesSet
+	var me *MappingError
+	if !errors.As(err, &me) || me.Path != "Addresses[1].Zip" {
+		t.Errorf("MapE() error path = %v, want %v", me, "Addresses[1].Zip")
 	}
-	type test struct {
-		name string
-		args args
-		want complexTargetMap
+
+	assertPanic(func() { Map(o, &target{}) }, "mapping error: field 'Addresses[1].Zip': invalid zip", t)
+}
+
+func Test_MapE_rule_nil_error(t *testing.T) {
+	type source struct{ Price string }
+	type target struct{ Price int }
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules: RulesSet{
+			"Price": func(s source) (int, error) { return len(s.Price), nil },
+		},
+	})
+
+	d := &target{}
+	if err := MapE(&source{Price: "1234"}, d); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
 	}
-	tests := []test{
-		{
-			name: "Source map with same type as the destination",
-			args: args{
-				source: &complexSourceMap{
-					unExComplexMap: map[string]source{
-						"one": {
-							field1: "one val",
-							field2: 1,
-						},
-						"two": {
-							field1: "two val",
-							field2: 2,
-						},
-					},
-				},
-				target: &complexTargetMap{},
-				rules: RulesSet{
-					"ExComplexMap": "unExComplexMap",
-				},
+	if d.Price != 4 {
+		t.Errorf("MapE() = %v, want %v", d.Price, 4)
+	}
+}
+
+func Test_MapE_source_target_type_errors(t *testing.T) {
+	if err := MapE(struct{}{}, &struct{}{}); err == nil || err.Error() != "rules error: source must be a pointer" {
+		t.Errorf("MapE() error = %v, want %v", err, "rules error: source must be a pointer")
+	}
+}
+
+type ctxKey string
+
+func Test_MapContext_rule_context(t *testing.T) {
+	type source struct{ Currency string }
+	type target struct{ CurrencyName string }
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules: RulesSet{
+			"CurrencyName": func(ctx context.Context, s source) string {
+				return ctx.Value(ctxKey("names")).(map[string]string)[s.Currency]
 			},
-			want: complexTargetMap{
-				ExComplexMap: map[string]target{},
+		},
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey("names"), map[string]string{"CLP": "Chilean peso"})
+	d := &target{}
+	if err := MapContext(ctx, &source{Currency: "CLP"}, d); err != nil {
+		t.Fatalf("MapContext() error = %v, want nil", err)
+	}
+	if d.CurrencyName != "Chilean peso" {
+		t.Errorf("MapContext() = %v, want %v", d.CurrencyName, "Chilean peso")
+	}
+}
+
+func Test_MapContext_canceled(t *testing.T) {
+	type itemSource struct{ Field string }
+	type itemTarget struct{ Field string }
+	type source struct{ Items []itemSource }
+	type target struct{ Items []itemTarget }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	withRegistry(t, RulesDefinition{
+		Source: itemSource{},
+		Target: itemTarget{},
+		Rules: RulesSet{
+			"Field": func(i itemSource) string {
+				calls++
+				cancel()
+				return i.Field
 			},
 		},
+	})
+
+	o := &source{Items: []itemSource{{Field: "item1"}, {Field: "item2"}, {Field: "item3"}}}
+	err := MapContext(ctx, o, &target{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("MapContext() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("MapContext() rule calls = %d, want %d", calls, 1)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.rules != nil {
-				rulesRegistry = make(mapperRulesRegistry)
-				RegisterRulesDefinitions(RulesDefinition{
-					*tt.args.source,
-					*tt.args.target,
-					tt.args.rules,
-				})
-			}
-			Map(tt.args.source, tt.args.target)
-			if !reflect.DeepEqual(tt.args.target, &tt.want) {
-				t.Errorf("Map() = %#v, want %#v", tt.args.target, &tt.want)
-			}
-		})
+	if err := MapContext(ctx, o, &target{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("MapContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_MapE_arrays_different_length(t *testing.T) {
+	type itemSource struct{ Field string }
+	type itemTarget struct{ Field string }
+	type source struct{ Items [2]itemSource }
+	type target struct{ Items [3]itemTarget }
+
+	d := &target{}
+	if err := MapE(&source{Items: [2]itemSource{{Field: "item1"}, {Field: "item2"}}}, d); err != nil {
+		t.Fatalf("MapE() error = %v, want nil", err)
+	}
+	want := &target{Items: [3]itemTarget{{Field: "item1"}, {Field: "item2"}, {}}}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("MapE() = %v, want %v", d, want)
+	}
+}
+
+func Test_MapE_recovers_panic(t *testing.T) {
+	type source struct{ Name string }
+	type target struct{ Name string }
+
+	withRegistry(t, RulesDefinition{
+		Source: source{},
+		Target: target{},
+		Rules:  RulesSet{"Name": func(s source) string { panic("boom") }},
+	})
+
+	err := MapE(&source{}, &target{})
+	var me *MappingError
+	if !errors.Is(err, ErrPanic) || !errors.As(err, &me) || me.Path != "Name" {
+		t.Errorf("MapE() error = %v, want %v at 'Name'", err, ErrPanic)
 	}
 }
@@ -0,0 +1,92 @@
+package structsconvtest
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// maxFillDepth limits the nesting of the values created by FillRandom, recursive types included.
+const maxFillDepth = 5
+
+// FillRandom sets random values to the exported fields of the struct pointed by v, nested structs, pointers,
+// slices, arrays and maps included. Collections get 1 to 3 items.
+//
+// It is intended for property-based tests, see FillRandomSeed for reproducible values.
+func FillRandom(v any) {
+	FillRandomSeed(v, time.Now().UnixNano())
+}
+
+// FillRandomSeed works like FillRandom, the same seed always produces the same values.
+func FillRandomSeed(v any, seed int64) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic("structsconvtest: FillRandom expects a non-nil pointer")
+	}
+	fillValue(rv.Elem(), rand.New(rand.NewSource(seed)), 0)
+}
+
+func fillValue(v reflect.Value, r *rand.Rand, depth int) {
+	if !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63n(100))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(r.Int63n(100)))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(r.Int63n(10000)) / 100)
+	case reflect.String:
+		v.SetString(randomString(r))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillValue(v.Field(i), r, depth+1)
+		}
+	case reflect.Ptr:
+		if depth >= maxFillDepth {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		fillValue(p.Elem(), r, depth+1)
+		v.Set(p)
+	case reflect.Slice:
+		if depth >= maxFillDepth {
+			return
+		}
+		n := 1 + r.Intn(3)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			fillValue(s.Index(i), r, depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillValue(v.Index(i), r, depth+1)
+		}
+	case reflect.Map:
+		if depth >= maxFillDepth {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		for i := 1 + r.Intn(3); i > 0; i-- {
+			k := reflect.New(v.Type().Key()).Elem()
+			fillValue(k, r, depth+1)
+			e := reflect.New(v.Type().Elem()).Elem()
+			fillValue(e, r, depth+1)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	}
+}
+
+func randomString(r *rand.Rand) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	b := make([]byte, 1+r.Intn(8))
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
@@ -0,0 +1,72 @@
+package structsconvtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden makes AssertGolden write the golden files instead of comparing them, run the tests with
+// -structsconvtest.update to update them.
+var updateGolden = flag.Bool("structsconvtest.update", false, "update the golden files of structsconvtest.AssertGolden")
+
+// AssertGolden fails the test if got, rendered as indented JSON, is not equal to the golden file
+// testdata/<name>.golden of the package under test. The differences are reported one per line as
+// "line N: -golden +got".
+//
+// With the -structsconvtest.update flag the golden file is written instead, e.g.
+//
+//	go test ./... -structsconvtest.update
+//
+// JSON renders the maps sorted by key, so the output is the same on every run. Unexported fields are not rendered.
+func AssertGolden(t testing.TB, name string, got any) {
+	t.Helper()
+	b, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("golden %s: rendering %T failed: %v", name, got, err)
+	}
+	b = append(b, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v, run the tests with -structsconvtest.update to create it", name, err)
+	}
+	if !bytes.Equal(want, b) {
+		t.Errorf("golden %s mismatch (-golden +got):\n%s", name, diffLines(string(want), string(b)))
+	}
+}
+
+// diffLines returns the lines that differ between want and got, compared line by line.
+func diffLines(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var lines []string
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			lines = append(lines, fmt.Sprintf("line %d: -%s +%s", i+1, strings.TrimSpace(wl), strings.TrimSpace(gl)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
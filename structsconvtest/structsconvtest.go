@@ -0,0 +1,186 @@
+// Package structsconvtest provides test helpers for the structsconv mappings.
+package structsconvtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rendis/structsconv"
+)
+
+// AssertRoundTrip maps src to mid and mid back to back, using the registered rules, and fails the test if back is
+// not equal to src. The differences are reported one per line as "path: -src +back".
+//
+// src can be a struct or a pointer to a struct, mid and back must be pointers to structs.
+// args are passed to both mappings.
+func AssertRoundTrip(t testing.TB, src, mid, back any, args ...any) {
+	t.Helper()
+	sv := reflect.ValueOf(src)
+	if sv.Kind() != reflect.Ptr {
+		p := reflect.New(sv.Type())
+		p.Elem().Set(sv)
+		sv = p
+	}
+	if err := structsconv.MapE(sv.Interface(), mid, args...); err != nil {
+		t.Fatalf("round trip: mapping %s to %T failed: %v", sv.Type(), mid, err)
+	}
+	if err := structsconv.MapE(mid, back, args...); err != nil {
+		t.Fatalf("round trip: mapping %T to %T failed: %v", mid, back, err)
+	}
+	if d := Diff(sv.Elem().Interface(), reflect.ValueOf(back).Elem().Interface()); d != "" {
+		t.Errorf("round trip: %s -> %T -> %T mismatch (-src +back):\n%s", sv.Elem().Type(), mid, back, d)
+	}
+}
+
+// AssertAllFieldsMapped fails the test for every target field, nested fields included, that is unmapped or has
+// a warning, e.g. incompatible types. Ignored fields and fields filled by rules are considered mapped.
+func AssertAllFieldsMapped(t testing.TB, source, target any) {
+	t.Helper()
+	plan, err := structsconv.Explain(source, target)
+	if err != nil {
+		t.Fatalf("all fields mapped: %v", err)
+	}
+	for _, problem := range planProblems(plan, "") {
+		t.Errorf("all fields mapped: %s", problem)
+	}
+}
+
+// planProblems returns the unmapped fields and the warnings of the plan, with the path of the target field.
+func planProblems(plan *structsconv.Plan, prefix string) []string {
+	var problems []string
+	for _, f := range plan.Fields {
+		path := prefix + f.Target
+		if f.Strategy == structsconv.StrategyUnmapped {
+			problems = append(problems, fmt.Sprintf("(%s -> %s) field '%s' is unmapped", plan.Source, plan.Target, path))
+			continue
+		}
+		for _, w := range f.Warnings {
+			problems = append(problems, fmt.Sprintf("(%s -> %s) field '%s': %s", plan.Source, plan.Target, path, w))
+		}
+		if f.Nested != nil && !f.Nested.Recursive {
+			problems = append(problems, planProblems(f.Nested, path+".")...)
+		}
+	}
+	return problems
+}
+
+// Diff returns the differences between want and got, one per line as "path: -want +got", or "" if they are equal.
+//
+// Unexported fields are compared too, and cyclic values are compared once, as in reflect.DeepEqual.
+func Diff(want, got any) string {
+	var lines []string
+	diffValues("", reflect.ValueOf(want), reflect.ValueOf(got), &lines, make(map[visit]bool))
+	return strings.Join(lines, "\n")
+}
+
+// visit is a pair of pointers, slices or maps already compared by diffValues.
+type visit struct {
+	want, got uintptr
+	typ       reflect.Type
+}
+
+func diffValues(path string, want, got reflect.Value, lines *[]string, visited map[visit]bool) {
+	report := func(w, g any) {
+		p := path
+		if p == "" {
+			p = "."
+		}
+		*lines = append(*lines, fmt.Sprintf("%s: -%v +%v", p, w, g))
+	}
+
+	switch {
+	case !want.IsValid() || !got.IsValid():
+		if want.IsValid() != got.IsValid() {
+			report(want, got)
+		}
+		return
+	case want.Type() != got.Type():
+		report(want.Type(), got.Type())
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v := (visit{want.Pointer(), got.Pointer(), want.Type()}); v.want != 0 && v.got != 0 {
+			if visited[v] {
+				return
+			}
+			visited[v] = true
+		}
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				report(want, got)
+			}
+			return
+		}
+		diffValues(path, want.Elem(), got.Elem(), lines, visited)
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			diffValues(joinPath(path, want.Type().Field(i).Name), want.Field(i), got.Field(i), lines, visited)
+		}
+	case reflect.Slice, reflect.Array:
+		if want.Kind() == reflect.Slice && want.IsNil() != got.IsNil() {
+			report(want, got)
+			return
+		}
+		if want.Len() != got.Len() {
+			report(fmt.Sprintf("len %d", want.Len()), fmt.Sprintf("len %d", got.Len()))
+			return
+		}
+		for i := 0; i < want.Len(); i++ {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), want.Index(i), got.Index(i), lines, visited)
+		}
+	case reflect.Map:
+		if want.IsNil() != got.IsNil() {
+			report(want, got)
+			return
+		}
+		for _, k := range sortedKeys(want, got) {
+			kp := fmt.Sprintf("%s[%v]", path, k)
+			w, g := want.MapIndex(k), got.MapIndex(k)
+			switch {
+			case !g.IsValid():
+				*lines = append(*lines, fmt.Sprintf("%s: -%v +<missing>", kp, w))
+			case !w.IsValid():
+				*lines = append(*lines, fmt.Sprintf("%s: -<missing> +%v", kp, g))
+			default:
+				diffValues(kp, w, g, lines, visited)
+			}
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if want.Pointer() != got.Pointer() {
+			report(want, got)
+		}
+	default:
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			report(want, got)
+		}
+	}
+}
+
+// sortedKeys returns the keys of both maps, once and sorted by their text, so the differences are reported in
+// the same order on every call.
+func sortedKeys(want, got reflect.Value) []reflect.Value {
+	keys := want.MapKeys()
+	for _, k := range got.MapKeys() {
+		if !want.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+	return keys
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
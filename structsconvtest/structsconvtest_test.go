@@ -0,0 +1,218 @@
+package structsconvtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rendis/structsconv"
+)
+
+// fakeTB records the failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+	f.fatal = true
+}
+
+type bookDto struct {
+	Title string
+	Tags  []string
+}
+
+type userDto struct {
+	UserID int
+	Name   string
+	Books  []bookDto
+	Extra  map[string]int
+}
+
+type book struct {
+	Title string
+	Tags  []string
+}
+
+type user struct {
+	ID    int
+	Name  string
+	Books []*book
+	Extra map[string]int
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+type nodeDto struct {
+	Value int
+	Next  *nodeDto
+}
+
+type userSummary struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func init() {
+	structsconv.SetLogger(nil)
+	structsconv.RegisterRulesDefinitions(
+		structsconv.RulesDefinition{Source: userDto{}, Target: user{}, Rules: structsconv.RulesSet{"ID": "UserID"}},
+		structsconv.RulesDefinition{Source: user{}, Target: userDto{}, Rules: structsconv.RulesSet{"UserID": "ID"}},
+	)
+}
+
+func Test_AssertRoundTrip(t *testing.T) {
+	var src userDto
+	FillRandomSeed(&src, 1)
+	AssertRoundTrip(t, src, &user{}, &userDto{})
+}
+
+func Test_AssertRoundTrip_mismatch(t *testing.T) {
+	ft := &fakeTB{}
+	src := &userDto{UserID: 1, Name: "name", Books: []bookDto{{Title: "title"}}}
+	AssertRoundTrip(ft, src, &userSummary{}, &userDto{})
+
+	if len(ft.errors) != 1 ||
+		!strings.Contains(ft.errors[0], "UserID: -1 +0") ||
+		!strings.Contains(ft.errors[0], "Books: -[{title []}] +[]") {
+		t.Errorf("AssertRoundTrip() errors = %v, want UserID and Books mismatch", ft.errors)
+	}
+}
+
+func Test_AssertAllFieldsMapped(t *testing.T) {
+	AssertAllFieldsMapped(t, userDto{}, user{})
+
+	ft := &fakeTB{}
+	AssertAllFieldsMapped(ft, userDto{}, userSummary{})
+	want := []string{
+		"all fields mapped: (structsconvtest.userDto -> structsconvtest.userSummary) field 'ID' is unmapped",
+		"all fields mapped: (structsconvtest.userDto -> structsconvtest.userSummary) field 'Email' is unmapped",
+	}
+	if fmt.Sprint(ft.errors) != fmt.Sprint(want) {
+		t.Errorf("AssertAllFieldsMapped() errors = %v, want %v", ft.errors, want)
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	want := user{ID: 1, Books: []*book{{Title: "a"}}, Extra: map[string]int{"a": 1}}
+	got := user{ID: 2, Books: []*book{{Title: "b"}}, Extra: map[string]int{"b": 1}}
+
+	d := Diff(want, got)
+	for _, line := range []string{"ID: -1 +2", "Books[0].Title: -a +b", "Extra[a]: -1 +<missing>", "Extra[b]: -<missing> +1"} {
+		if !strings.Contains(d, line) {
+			t.Errorf("Diff() = %q, want to contain %q", d, line)
+		}
+	}
+	if d := Diff(want, want); d != "" {
+		t.Errorf("Diff() = %q, want empty", d)
+	}
+
+	want.Extra = map[string]int{"c": 1, "a": 1, "d": 1}
+	got.Extra = map[string]int{"b": 1, "a": 2, "e": 1}
+	wantExtra := "Extra[a]: -1 +2\nExtra[b]: -<missing> +1\nExtra[c]: -1 +<missing>\nExtra[d]: -1 +<missing>\nExtra[e]: -<missing> +1"
+	for i := 0; i < 10; i++ {
+		if d := Diff(want.Extra, got.Extra); d != strings.ReplaceAll(wantExtra, "Extra", "") {
+			t.Fatalf("Diff() = %q, want %q", d, wantExtra)
+		}
+	}
+}
+
+func Test_Diff_cycle(t *testing.T) {
+	newRing := func(values ...int) *node {
+		first := &node{Value: values[0]}
+		last := first
+		for _, v := range values[1:] {
+			last.Next = &node{Value: v}
+			last = last.Next
+		}
+		last.Next = first
+		return first
+	}
+
+	if d := Diff(newRing(1, 2), newRing(1, 2)); d != "" {
+		t.Errorf("Diff() = %q, want empty", d)
+	}
+	if d, want := Diff(newRing(1, 2), newRing(1, 3)), "Next.Value: -2 +3"; d != want {
+		t.Errorf("Diff() = %q, want %q", d, want)
+	}
+	AssertRoundTrip(t, newRing(1, 2, 3), &nodeDto{}, &node{})
+}
+
+func Test_AssertGolden(t *testing.T) {
+	var got user
+	src := userDto{UserID: 1, Name: "ann", Books: []bookDto{{Title: "a", Tags: []string{"x"}}}, Extra: map[string]int{"b": 2, "a": 1}}
+	structsconv.Map(&src, &got)
+	AssertGolden(t, "user", got)
+	if *updateGolden { // the failures below would be written as golden files
+		return
+	}
+
+	ft := &fakeTB{}
+	got.Name = "bob"
+	AssertGolden(ft, "user", got)
+	if want := `line 3: -"Name": "ann", +"Name": "bob",`; len(ft.errors) != 1 || !strings.Contains(ft.errors[0], want) {
+		t.Errorf("AssertGolden() errors = %v, want to contain %q", ft.errors, want)
+	}
+
+	ft = &fakeTB{}
+	AssertGolden(ft, "missing", got)
+	if !ft.fatal {
+		t.Errorf("AssertGolden() without golden file did not fail")
+	}
+}
+
+func Test_AssertGolden_update(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(dir)
+	*updateGolden = true
+	defer func() { *updateGolden = false }()
+
+	AssertGolden(t, "summary", userSummary{ID: 1})
+	b, err := os.ReadFile(filepath.Join("testdata", "summary.golden"))
+	if want := "{\n  \"ID\": 1,\n  \"Name\": \"\",\n  \"Email\": \"\"\n}\n"; err != nil || string(b) != want {
+		t.Errorf("golden file = %q, %v, want %q", b, err, want)
+	}
+}
+
+func Test_FillRandomSeed(t *testing.T) {
+	type node struct {
+		Value int
+		Next  *node
+	}
+	var a, b userDto
+	FillRandomSeed(&a, 7)
+	FillRandomSeed(&b, 7)
+	if d := Diff(a, b); d != "" {
+		t.Errorf("FillRandomSeed() with the same seed differs:\n%s", d)
+	}
+	if a.Name == "" || len(a.Books) == 0 || len(a.Extra) == 0 {
+		t.Errorf("FillRandomSeed() = %+v, want all fields filled", a)
+	}
+
+	var n node
+	FillRandom(&n)
+	if n.Next == nil {
+		t.Errorf("FillRandom() = %+v, want recursive pointer filled", n)
+	}
+}
@@ -0,0 +1,91 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrSwitchCaseNotFound is returned when the value of the discriminator field of a Switch rule has no case.
+var ErrSwitchCaseNotFound = errors.New("switch case not found")
+
+// SwitchRule is a rule that maps the whole source struct to a type chosen by the value of a source field, see Switch.
+type SwitchRule struct {
+	field string
+	cases map[string]reflect.Type
+}
+
+// Switch returns a rule that maps the source struct to the type of the case selected by the value of the
+// discriminator field, a string field of the source struct. The result is set to the target field, usually of an
+// interface type implemented by every case:
+//
+//	"Payment": structsconv.Switch("Type", map[string]any{
+//		"card": domain.Card{},
+//		"pix":  &domain.Pix{},
+//	}),
+//
+// The source struct is mapped to the case type with the rules registered for the pair. A value without case makes
+// the mapping fail with an error wrapping ErrSwitchCaseNotFound.
+func Switch(field string, cases map[string]any) SwitchRule {
+	rule := SwitchRule{field: field, cases: make(map[string]reflect.Type, len(cases))}
+	for value, c := range cases {
+		if c == nil {
+			log.Panicf("ERROR: Switch case '%s' of field '%s' has no type.", value, field)
+		}
+		rule.cases[value] = reflect.TypeOf(c)
+	}
+	return rule
+}
+
+// String returns the discriminator field and the cases of the rule, sorted by value.
+func (r SwitchRule) String() string {
+	values := make([]string, 0, len(r.cases))
+	for value := range r.cases {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for i, value := range values {
+		values[i] = fmt.Sprintf("%s: %s", value, r.cases[value])
+	}
+	return fmt.Sprintf("switch %s {%s}", r.field, strings.Join(values, ", "))
+}
+
+// checkSwitch checks the Switch rule of the target field ruleKey
+//   - the discriminator is a string field of the source struct
+//   - every case type is assignable to the target field
+func checkSwitch(rule SwitchRule, ruleKey string, key rulesKey) {
+	sf, exist := key.source.FieldByName(rule.field)
+	if !exist || sf.Type.Kind() != reflect.String {
+		log.Panicf(
+			"ERROR: (%s -> %s) Switch field '%s' must be a string field of source struct %s.\n",
+			key.source.String(), key.target.String(), rule.field, key.source.String(),
+		)
+	}
+	tf, _ := key.target.FieldByName(ruleKey)
+	for value, t := range rule.cases {
+		if !t.AssignableTo(tf.Type) {
+			log.Panicf(
+				"ERROR: (%s -> %s) Switch case '%s' of field '%s' has type '%s', not assignable to '%s'.\n",
+				key.source.String(), key.target.String(), value, ruleKey, t, tf.Type,
+			)
+		}
+	}
+}
+
+// applySwitch maps the source struct to the type of the case selected by the discriminator field and sets it to the target.
+func applySwitch(source, targetValue reflect.Value, rule SwitchRule, state *mappingState) error {
+	discriminator := source.FieldByName(rule.field)
+	t, exists := rule.cases[discriminator.String()]
+	if !exists {
+		return fmt.Errorf("%w: %s = '%s'", ErrSwitchCaseNotFound, rule.field, discriminator.String())
+	}
+	item := reflect.New(t).Elem()
+	if _, err := fieldToField(source, item, state); err != nil {
+		return err
+	}
+	mappingDirectMapping(item, targetValue, state)
+	return nil
+}
@@ -0,0 +1,137 @@
+package structsconv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type paymentEventDto struct {
+	ID     string
+	Type   string
+	Number string
+	Key    string
+}
+
+type paymentEvent struct {
+	ID      string
+	Payment paymentMethod
+}
+
+func registerPaymentEventRules(t *testing.T) {
+	withRegistry(t,
+		RulesDefinition{
+			Source: paymentEventDto{},
+			Target: paymentEvent{},
+			Rules: RulesSet{
+				"Payment": Switch("Type", map[string]any{
+					"card": card{},
+					"pix":  &pix{},
+				}),
+			},
+		},
+		RulesDefinition{
+			Source: paymentEventDto{},
+			Target: card{},
+			Rules: RulesSet{
+				"Last4":  func(e paymentEventDto) string { return e.Number[len(e.Number)-4:] },
+				"holder": nil,
+			},
+		},
+	)
+}
+
+func Test_Map_switch(t *testing.T) {
+	registerPaymentEventRules(t)
+	tests := []struct {
+		name string
+		src  paymentEventDto
+		want paymentEvent
+	}{
+		{
+			name: "card",
+			src:  paymentEventDto{ID: "1", Type: "card", Number: "4111111111111111"},
+			want: paymentEvent{ID: "1", Payment: card{Last4: "1111"}},
+		},
+		{
+			name: "pix",
+			src:  paymentEventDto{ID: "2", Type: "pix", Key: "k1"},
+			want: paymentEvent{ID: "2", Payment: &pix{Key: "k1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got paymentEvent
+			if err := MapE(&tt.src, &got); err != nil {
+				t.Fatalf("MapE() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MapE() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Map_switch_caseNotFound(t *testing.T) {
+	registerPaymentEventRules(t)
+	src := paymentEventDto{Type: "boleto"}
+	var got paymentEvent
+	err := MapE(&src, &got)
+	if !errors.Is(err, ErrSwitchCaseNotFound) {
+		t.Fatalf("MapE() error = %v, want %v", err, ErrSwitchCaseNotFound)
+	}
+	want := "mapping error: field 'Payment': switch case not found: Type = 'boleto'"
+	if err.Error() != want {
+		t.Errorf("MapE() error = %s, want %s", err, want)
+	}
+}
+
+func Test_checkSwitch_panics(t *testing.T) {
+	key := buildKey(paymentEventDto{}, paymentEvent{})
+	tests := []struct {
+		name         string
+		rules        RulesSet
+		wantContains string
+	}{
+		{
+			name:         "Discriminator is not present in source,panic expected",
+			wantContains: "Switch field 'Kind' must be a string field of source struct structsconv.paymentEventDto",
+			rules: RulesSet{
+				"Payment": Switch("Kind", map[string]any{"card": card{}}),
+			},
+		},
+		{
+			name:         "Case type is not assignable to target field,panic expected",
+			wantContains: "Switch case 'pix' of field 'Payment' has type 'structsconv.pix', not assignable to 'structsconv.paymentMethod'",
+			rules: RulesSet{
+				"Payment": Switch("Type", map[string]any{"pix": pix{}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f = func() { checkMapperRules(key, tt.rules) }
+			assertPanic(f, tt.wantContains, t)
+		})
+	}
+}
+
+func Test_Explain_switch(t *testing.T) {
+	registerPaymentEventRules(t)
+	plan, err := Explain(paymentEventDto{}, paymentEvent{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	got := plan.Fields[1]
+	want := FieldPlan{
+		Target:   "Payment",
+		Type:     "structsconv.paymentMethod",
+		Strategy: StrategySwitch,
+		Source:   "switch Type {card: structsconv.card, pix: *structsconv.pix}",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,473 @@
+package structsconv
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrCoercion is returned by FromMap when a map value can't be converted to the type of the target field.
+var ErrCoercion = errors.New("cannot convert")
+
+var (
+	mapAnyType          = reflect.TypeOf(map[string]any(nil))
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+)
+
+// MapOption configures ToMap and FromMap.
+type MapOption func(*mapOptions)
+
+type mapOptions struct {
+	keyTag string
+}
+
+// WithKeyTag makes ToMap and FromMap use the name in the given struct tag as the map key of the fields, like "json"
+// or "bson". Fields tagged "-" are skipped, and fields without tag name use their own name.
+func WithKeyTag(tag string) MapOption {
+	return func(o *mapOptions) {
+		o.keyTag = tag
+	}
+}
+
+// ToMap converts the source struct, or pointer to struct, to a map[string]any using the default Mapper.
+//
+// The keys are the names of the exported fields, see WithKeyTag. Nested structs are converted to nested maps, slices
+// and arrays to []any, and values implementing json.Marshaler or encoding.TextMarshaler, like time.Time, are kept.
+//
+// The rules registered with a map[string]any{} target are applied: the rule keys are the map keys, renaming the
+// source fields or holding the value returned by a rule function.
+func ToMap(source any, opts ...MapOption) (map[string]any, error) {
+	return defaultMapper().ToMap(source, opts...)
+}
+
+// FromMap fills the target struct, a pointer to a struct, from a map[string]any using the default Mapper.
+//
+// It is the inverse of ToMap: nested maps fill nested structs, and the values are converted to the field types, like
+// the float64 numbers decoded by encoding/json to int. The rules registered with a map[string]any{} source are
+// applied: rename rules name the map key of the field, and rule functions receive the map.
+func FromMap(source map[string]any, target any, opts ...MapOption) error {
+	return defaultMapper().FromMap(source, target, opts...)
+}
+
+// ToMap works like the package level ToMap, with the Mapper options.
+func (m *Mapper) ToMap(source any, opts ...MapOption) (map[string]any, error) {
+	sourceV := reflect.ValueOf(source)
+	if sourceV.Kind() == reflect.Ptr && !sourceV.IsNil() {
+		sourceV = sourceV.Elem()
+	}
+	if sourceV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rules error: source must be a struct or a pointer to a struct")
+	}
+	// rule functions may request the source, copy it to an addressable value
+	root := reflect.New(sourceV.Type())
+	root.Elem().Set(sourceV)
+
+	state, err := m.newState(context.Background(), root, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &mapConverter{state: state, options: newMapOptions(opts), visiting: make(map[pointerKey]bool)}
+	var result map[string]any
+	err = state.run(func() error {
+		var err error
+		result, err = c.structToMap(root.Elem())
+		return err
+	})
+	return result, err
+}
+
+// FromMap works like the package level FromMap, with the Mapper options.
+func (m *Mapper) FromMap(source map[string]any, target any, opts ...MapOption) error {
+	targetV := reflect.ValueOf(target)
+	if targetV.Kind() != reflect.Ptr || targetV.IsNil() || targetV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rules error: target must be a pointer to a struct")
+	}
+	sourceV := reflect.ValueOf(&source)
+
+	state, err := m.newState(context.Background(), sourceV, nil)
+	if err != nil {
+		return err
+	}
+	c := &mapConverter{state: state, options: newMapOptions(opts)}
+	return state.run(func() error {
+		return c.mapToStruct(sourceV.Elem(), targetV.Elem())
+	})
+}
+
+func newMapOptions(opts []MapOption) mapOptions {
+	var o mapOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// mapConverter converts structs to maps and back, in a ToMap or FromMap call.
+type mapConverter struct {
+	state    *mappingState
+	options  mapOptions
+	visiting map[pointerKey]bool // pointers being converted by ToMap, to detect cycles
+}
+
+// fieldKey returns the map key of a struct field, false if the field is not converted.
+func (c *mapConverter) fieldKey(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	if c.options.keyTag == "" {
+		return f.Name, true
+	}
+	name, _, _ := strings.Cut(f.Tag.Get(c.options.keyTag), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// structToMap converts a struct to a map, applying the rules registered for the struct type to map[string]any.
+func (c *mapConverter) structToMap(source reflect.Value) (map[string]any, error) {
+	if err := c.state.enterStruct(); err != nil {
+		return nil, err
+	}
+	defer c.state.leaveStruct()
+
+	rules := c.state.registry.rules[rulesKey{source.Type(), mapAnyType}]
+	renamed := make(map[string]bool)
+	for _, r := range rules {
+		if name, ok := r.(string); ok {
+			renamed[name] = true
+		}
+	}
+
+	result := make(map[string]any, source.NumField())
+	for i := 0; i < source.NumField(); i++ {
+		f := source.Type().Field(i)
+		key, ok := c.fieldKey(f)
+		if _, hasRule := rules[key]; !ok || hasRule || renamed[f.Name] {
+			continue
+		}
+		v, err := c.toMapValue(source.Field(i), f.Name, source)
+		if err != nil {
+			return nil, wrapFieldError(f.Name, err)
+		}
+		result[key] = v
+	}
+
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, err := c.applyToMapRule(source, k, rules[k])
+		if err != nil {
+			return nil, wrapFieldError(k, err)
+		}
+		if rules[k] != nil { // nil rule, the key is ignored
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+// applyToMapRule returns the map value of the rule of the given key.
+func (c *mapConverter) applyToMapRule(source reflect.Value, key string, rule any) (any, error) {
+	switch r := rule.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return c.toMapValue(source.FieldByName(r), key, source)
+	default:
+		method := reflect.ValueOf(rule)
+		result := reflect.New(method.Type().Out(0)).Elem()
+		c.state.pushField(key, source)
+		err := callFunc(result, method, source, c.state)
+		c.state.pop()
+		if err != nil {
+			return nil, err
+		}
+		return c.toMapValue(result, key, source)
+	}
+}
+
+// toMapValue converts a value to its map representation, field is the name of the value in its owner struct.
+func (c *mapConverter) toMapValue(v reflect.Value, field string, owner reflect.Value) (any, error) {
+	c.state.pushField(field, owner)
+	defer c.state.pop()
+	return c.convertValue(v)
+}
+
+// convertValue converts a value to its map representation.
+func (c *mapConverter) convertValue(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Ptr {
+			if key, ok := newPointerKey(v, mapAnyType); ok {
+				if c.visiting[key] {
+					return nil, ErrCycle
+				}
+				c.visiting[key] = true
+				defer delete(c.visiting, key)
+			}
+		}
+		return c.convertValue(v.Elem())
+	case reflect.Struct:
+		return c.structToMap(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface(), nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			c.state.pushIndex(i)
+			item, err := c.convertValue(v.Index(i))
+			c.state.pop()
+			if err != nil {
+				return nil, wrapIndexError(i, err)
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), nil
+		}
+		items := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.state.pushKey(iter.Key())
+			item, err := c.convertValue(iter.Value())
+			c.state.pop()
+			if err != nil {
+				return nil, wrapKeyError(iter.Key(), err)
+			}
+			items[iter.Key().String()] = item
+		}
+		return items, nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// mapToStruct fills a struct from a map, applying the rules registered for map[string]any to the struct type.
+func (c *mapConverter) mapToStruct(source, target reflect.Value) error {
+	if err := c.state.enterStruct(); err != nil {
+		return err
+	}
+	defer c.state.leaveStruct()
+
+	rules := c.state.registry.rules[rulesKey{mapAnyType, target.Type()}]
+	for i := 0; i < target.NumField(); i++ {
+		f := target.Type().Field(i)
+		c.state.pushField(f.Name, source)
+		err := c.mapToField(source, target.Field(i), f, rules)
+		c.state.pop()
+		if err != nil {
+			return wrapFieldError(f.Name, err)
+		}
+	}
+	return nil
+}
+
+// mapToField fills a struct field from the map value of its key, or from its rule.
+func (c *mapConverter) mapToField(source, targetValue reflect.Value, f reflect.StructField, rules RulesSet) error {
+	key, ok := c.fieldKey(f)
+	if rule, exists := rules[f.Name]; exists {
+		switch r := rule.(type) {
+		case nil:
+			return nil
+		case string:
+			key, ok = r, true
+		default:
+			return callFunc(targetValue, reflect.ValueOf(rule), source, c.state)
+		}
+	}
+	if !ok {
+		return nil
+	}
+	value := source.MapIndex(reflect.ValueOf(key))
+	if !value.IsValid() {
+		return nil
+	}
+	return c.coerce(value.Elem(), targetValue)
+}
+
+// coerce sets the target to the value converted to the target type.
+func (c *mapConverter) coerce(v, target reflect.Value) error {
+	if !v.IsValid() { // nil value
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type().AssignableTo(target.Type()) {
+		target.Set(v)
+		return nil
+	}
+
+	if v.Type() == jsonNumberType {
+		return c.coerceJSONNumber(v.Interface().(json.Number), target)
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(target.Type().Elem())
+		if err := c.coerce(v, p.Elem()); err != nil {
+			return err
+		}
+		target.Set(p)
+		return nil
+	case reflect.Struct:
+		if v.Type() == mapAnyType {
+			return c.mapToStruct(v, target)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			return c.coerceItems(v, target)
+		}
+	case reflect.Map:
+		if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && target.Type().Key().Kind() == reflect.String {
+			return c.coerceMap(v, target)
+		}
+	}
+
+	if v.Kind() == reflect.String && reflect.PointerTo(target.Type()).Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v.String()))
+	}
+	if isNumberKind(v.Kind()) && isNumberKind(target.Kind()) {
+		return coerceNumber(v, target)
+	}
+	if v.Kind() == target.Kind() && v.Type().ConvertibleTo(target.Type()) { // named types, like strings or bools
+		target.Set(v.Convert(target.Type()))
+		return nil
+	}
+	return fmt.Errorf("%w %s to %s", ErrCoercion, v.Type(), target.Type())
+}
+
+// coerceItems fills the target slice or array from the items of the source slice or array.
+func (c *mapConverter) coerceItems(v, target reflect.Value) error {
+	if target.Kind() == reflect.Slice {
+		target.Set(reflect.MakeSlice(target.Type(), v.Len(), v.Len()))
+	}
+	for i := 0; i < v.Len() && i < target.Len(); i++ {
+		c.state.pushIndex(i)
+		err := c.coerce(v.Index(i), target.Index(i))
+		c.state.pop()
+		if err != nil {
+			return wrapIndexError(i, err)
+		}
+	}
+	return nil
+}
+
+// coerceMap fills the target map from the items of the source map, both with string keys.
+func (c *mapConverter) coerceMap(v, target reflect.Value) error {
+	m := reflect.MakeMapWithSize(target.Type(), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		item := reflect.New(target.Type().Elem()).Elem()
+		c.state.pushKey(iter.Key())
+		err := c.coerce(iter.Value(), item)
+		c.state.pop()
+		if err != nil {
+			return wrapKeyError(iter.Key(), err)
+		}
+		m.SetMapIndex(iter.Key().Convert(target.Type().Key()), item)
+	}
+	target.Set(m)
+	return nil
+}
+
+// coerceJSONNumber sets the target to the number decoded by a json.Decoder with UseNumber.
+func (c *mapConverter) coerceJSONNumber(n json.Number, target reflect.Value) error {
+	if i, err := n.Int64(); err == nil {
+		return c.coerce(reflect.ValueOf(i), target)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("%w %s to %s: %w", ErrCoercion, jsonNumberType, target.Type(), err)
+	}
+	return c.coerce(reflect.ValueOf(f), target)
+}
+
+// isNumberKind reports whether k is an integer or float kind.
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// coerceNumber sets the target to the number v, failing if the number is not exactly representable in the target type.
+func coerceNumber(v, target reflect.Value) error {
+	fail := func() error {
+		return fmt.Errorf("%w %s %v to %s", ErrCoercion, v.Type(), v.Interface(), target.Type())
+	}
+
+	switch {
+	case v.CanInt():
+		i := v.Int()
+		switch {
+		case target.CanInt() && !target.OverflowInt(i):
+			target.SetInt(i)
+		case target.CanUint() && i >= 0 && !target.OverflowUint(uint64(i)):
+			target.SetUint(uint64(i))
+		case target.CanFloat():
+			target.SetFloat(float64(i))
+		default:
+			return fail()
+		}
+	case v.CanUint():
+		u := v.Uint()
+		switch {
+		case target.CanInt() && u <= math.MaxInt64 && !target.OverflowInt(int64(u)):
+			target.SetInt(int64(u))
+		case target.CanUint() && !target.OverflowUint(u):
+			target.SetUint(u)
+		case target.CanFloat():
+			target.SetFloat(float64(u))
+		default:
+			return fail()
+		}
+	default:
+		f := v.Float()
+		switch {
+		case target.CanFloat() && !target.OverflowFloat(f):
+			target.SetFloat(f)
+		case f != math.Trunc(f) || math.IsInf(f, 0):
+			return fail()
+		case target.CanInt() && f >= math.MinInt64 && f < math.MaxInt64 && !target.OverflowInt(int64(f)):
+			target.SetInt(int64(f))
+		case target.CanUint() && f >= 0 && f < math.MaxUint64 && !target.OverflowUint(uint64(f)):
+			target.SetUint(uint64(f))
+		default:
+			return fail()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package structsconv
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type docAddress struct {
+	Street string
+	Zip    int
+}
+
+type docUser struct {
+	UserID    int64
+	Name      string
+	Age       uint8
+	Score     float32
+	Tags      []string
+	Address   docAddress
+	Previous  *docAddress
+	Addresses []docAddress
+	Labels    map[string]int
+	Created   time.Time
+	Password  string
+	internal  string
+}
+
+func registerDocRules(t *testing.T) {
+	withRegistry(t,
+		RulesDefinition{
+			Source: docUser{},
+			Target: map[string]any{},
+			Rules: RulesSet{
+				"id":        "UserID",
+				"Password":  nil,
+				"NameUpper": func(u docUser) string { return strings.ToUpper(u.Name) },
+			},
+		},
+		RulesDefinition{
+			Source: map[string]any{},
+			Target: docUser{},
+			Rules: RulesSet{
+				"UserID":   "id",
+				"Password": func(m map[string]any) string { return "from " + m["Name"].(string) },
+			},
+		},
+	)
+}
+
+func Test_ToMap(t *testing.T) {
+	registerDocRules(t)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := docUser{
+		UserID:    7,
+		Name:      "john",
+		Age:       30,
+		Score:     1.5,
+		Tags:      []string{"a"},
+		Address:   docAddress{Street: "main", Zip: 1},
+		Addresses: []docAddress{{Street: "second", Zip: 2}},
+		Labels:    map[string]int{"x": 1},
+		Created:   created,
+		Password:  "secret",
+		internal:  "internal",
+	}
+
+	got, err := ToMap(&src)
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	want := map[string]any{
+		"id":        int64(7),
+		"Name":      "john",
+		"NameUpper": "JOHN",
+		"Age":       uint8(30),
+		"Score":     float32(1.5),
+		"Tags":      []any{"a"},
+		"Address":   map[string]any{"Street": "main", "Zip": 1},
+		"Previous":  nil,
+		"Addresses": []any{map[string]any{"Street": "second", "Zip": 2}},
+		"Labels":    map[string]any{"x": 1},
+		"Created":   created,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+}
+
+func Test_ToMap_keyTag(t *testing.T) {
+	type source struct {
+		Name    string `json:"name,omitempty"`
+		Skipped string `json:"-"`
+		Plain   string
+	}
+	got, err := ToMap(source{Name: "n", Skipped: "s", Plain: "p"}, WithKeyTag("json"))
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	if want := map[string]any{"name": "n", "Plain": "p"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+}
+
+func Test_FromMap_json(t *testing.T) {
+	registerDocRules(t)
+	doc := `{
+		"id": 7,
+		"Name": "john",
+		"Age": 30,
+		"Score": 1.5,
+		"Tags": ["a"],
+		"Address": {"Street": "main", "Zip": 1},
+		"Previous": {"Street": "old", "Zip": 3},
+		"Addresses": [{"Street": "second", "Zip": 2}],
+		"Labels": {"x": 1},
+		"Created": "2024-01-02T03:04:05Z"
+	}`
+	var m map[string]any
+	if err := json.Unmarshal([]byte(doc), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	var got docUser
+	if err := FromMap(m, &got); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	want := docUser{
+		UserID:    7,
+		Name:      "john",
+		Age:       30,
+		Score:     1.5,
+		Tags:      []string{"a"},
+		Address:   docAddress{Street: "main", Zip: 1},
+		Previous:  &docAddress{Street: "old", Zip: 3},
+		Addresses: []docAddress{{Street: "second", Zip: 2}},
+		Labels:    map[string]int{"x": 1},
+		Created:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Password:  "from john",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromMap() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_ToMap_FromMap_roundTrip(t *testing.T) {
+	registerDocRules(t)
+	src := docUser{
+		UserID:    7,
+		Name:      "john",
+		Addresses: []docAddress{{Street: "second", Zip: 2}},
+		Labels:    map[string]int{"x": 1},
+		Created:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	m, err := ToMap(src)
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	var got docUser
+	if err := FromMap(m, &got); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	src.Password = "from john"
+	if !reflect.DeepEqual(got, src) {
+		t.Errorf("round trip = %+v, want %+v", got, src)
+	}
+}
+
+func Test_FromMap_coercion(t *testing.T) {
+	type target struct {
+		Int    int
+		Int8   int8
+		Uint   uint
+		Float  float64
+		Number int64
+		Named  time.Duration
+		Ptr    *int
+		Array  [2]int
+	}
+	tests := []struct {
+		name    string
+		source  map[string]any
+		want    target
+		wantErr string
+	}{
+		{
+			name:   "numbers",
+			source: map[string]any{"Int": 1.0, "Int8": 2, "Uint": 3.0, "Float": 4, "Number": json.Number("5"), "Named": 6.0, "Ptr": 7.0, "Array": []any{8.0, 9.0}},
+			want:   target{Int: 1, Int8: 2, Uint: 3, Float: 4, Number: 5, Named: 6, Ptr: func() *int { i := 7; return &i }(), Array: [2]int{8, 9}},
+		},
+		{
+			name:    "fraction to int",
+			source:  map[string]any{"Int": 1.5},
+			wantErr: "mapping error: field 'Int': cannot convert float64 1.5 to int",
+		},
+		{
+			name:    "overflow",
+			source:  map[string]any{"Int8": 300.0},
+			wantErr: "mapping error: field 'Int8': cannot convert float64 300 to int8",
+		},
+		{
+			name:    "negative to uint",
+			source:  map[string]any{"Uint": -1},
+			wantErr: "mapping error: field 'Uint': cannot convert int -1 to uint",
+		},
+		{
+			name:    "string to int",
+			source:  map[string]any{"Array": []any{1, "2"}},
+			wantErr: "mapping error: field 'Array[1]': cannot convert string to int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got target
+			err := FromMap(tt.source, &got)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrCoercion) || err.Error() != tt.wantErr {
+					t.Fatalf("FromMap() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromMap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ToMap_cycle(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "a"}
+	n.Next = n
+	if _, err := ToMap(n); !errors.Is(err, ErrCycle) {
+		t.Errorf("ToMap() error = %v, want %v", err, ErrCycle)
+	}
+}
@@ -1,24 +1,98 @@
 package structsconv
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 )
 
 // RulesDefinition is used to define the source, target and rules for mapping.
+//
+// Targets that keep their fields unexported can be built without accessing them:
+//   - Constructor is a function that builds the whole target, e.g. func(dto.OrderDto) (domain.Order, error).
+//     Its parameters are injected as in the rule functions, and it cannot be combined with Rules or Setters.
+//   - Setters makes the fields with a setter be set by calling it, e.g. SetName(v) for the fields Name and name.
+//     The value passed is the one of the rule of the field, or of the source field named after the setter (Name).
+//
+// BeforeMap and AfterMap are functions called before and after mapping every target of the definition, at any
+// nesting level. Their parameters are injected as in the rule functions, and a parameter of the target pointer type
+// receives the target being mapped. They return nothing or an error, which aborts the mapping.
+// After the AfterMap hook, the AfterMap() and Validate() error methods of the target are called, if it has them.
+//
+// Extends lists the bases of the Rules, RulesSet or RulesDefinition values merged in order and overridden by Rules.
+// Bases with different rules for the same field, or with function rules for it, conflict unless Rules overrides it.
+// Only the rules of a base definition are reused, and when its source type differs from Source but has the same
+// field shape, its rule functions receive the source converted to their parameter type.
 type RulesDefinition struct {
-	Source interface{}
-	Target interface{}
-	Rules  RulesSet
+	Source      interface{}
+	Target      interface{}
+	Rules       RulesSet
+	Constructor interface{}
+	Setters     bool
+	BeforeMap   interface{}
+	AfterMap    interface{}
+	Extends     []interface{}
 }
 
 // RulesSet is a set of rules for mapping 2 specific structs, where the rulesKey is the name of the target field.
 // and value is the rule for the mapping. Value can be:
 //  - a string, which is the name of the source field
-//  - a function, which will be called to get the target value
+//  - a function, which will be called to get the target value; it may also return an error as second value
 type RulesSet map[string]interface{}
 
-// groupedArgs groups the arguments map by their type.
-type groupedArgs map[reflect.Type][]interface{}
+// errorType is the reflect.Type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// contextType is the reflect.Type of the context.Context interface.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// groupedArgs groups the arguments by their type, in the order of the first argument of each type.
+type groupedArgs []argsGroup
+
+// argsGroup holds the arguments of the same type, in the order they were passed.
+type argsGroup struct {
+	t      reflect.Type
+	values []reflect.Value
+}
+
+// find returns the position of the group of type t, -1 if there are no arguments of type t.
+func (g groupedArgs) find(t reflect.Type) int {
+	for i := range g {
+		if g[i].t == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingState holds the state of a single mapping call, it is shared by all the nesting levels.
+type mappingState struct {
+	mapper   *Mapper
+	ctx      context.Context
+	args     groupedArgs
+	userArgs []interface{}  // arguments passed to Map, without the named ones
+	argTypes []reflect.Type // distinct types of the arguments passed to Map, in order
+	named    map[string]interface{}
+	frames   []mappingFrame  // position of the value being mapped, from the root
+	pointers *mappedPointers // source pointers already mapped, to preserve shared references
+	depth    int             // number of nested structs being mapped
+	forkedOf *mappingState   // state that started this parallel worker, nil if it is not a worker
+	order    int             // position of the item mapped by a parallel worker, in the order of a sequential mapping
+	cloning  bool            // Clone call, the AfterMap() and Validate() methods of the targets are not called
+	registry *registry       // registry when the mapping started, used until it ends
+	params   []reflect.Value // buffer of the rule function parameters, reused between calls
+}
+
+// mappingFrame is a step in the path from the root to the value being mapped, it is either:
+//  - a target field, with the source struct that contains it (owner)
+//  - an item of a slice or array (index)
+//  - an item of a map (key)
+type mappingFrame struct {
+	field string
+	owner reflect.Value
+	index int
+	key   reflect.Value
+}
 
 // rulesKey identifies the rules for specific mapping from structure to structure.
 type rulesKey struct {
@@ -38,6 +112,7 @@ type mapperRulesRegistry map[rulesKey]RulesSet
 //  - ptrMapping			 (5): the pointers are processed using the mapping
 //  - ignoreMapping 		 (6): mapping will be ignored
 //  - incompatibleTypes		 (7): incompatible types, so the mapping will be ignored
+//  - interfaceMapping		 (8): the target interface is processed using the registered implementations
 type processingResultType int
 
 const (
@@ -49,8 +124,35 @@ const (
 	ptrMapping
 	ignoreMapping
 	incompatibleTypes
+	interfaceMapping
 )
 
+// String returns the name of the processing result type.
+func (p processingResultType) String() string {
+	switch p {
+	case structsMapping:
+		return "structs"
+	case slicesMapping:
+		return "slices"
+	case arraysMapping:
+		return "arrays"
+	case mapsMapping:
+		return "maps"
+	case directMapping:
+		return "direct"
+	case ptrMapping:
+		return "ptr"
+	case ignoreMapping:
+		return "ignore"
+	case incompatibleTypes:
+		return "incompatible"
+	case interfaceMapping:
+		return "interface"
+	default:
+		return fmt.Sprintf("processingResultType(%d)", int(p))
+	}
+}
+
 // buildKey builds a rulesKey from the source and target types.
 func buildKey(source, target interface{}) rulesKey {
 	return rulesKey{
@@ -59,9 +161,12 @@ func buildKey(source, target interface{}) rulesKey {
 	}
 }
 
-// getMappingType returns the mapping type for the given values.
-func getMappingType(sourceValue, targetValue reflect.Value) processingResultType {
+// getMappingType returns the mapping type for the given values, with the implementations of the registry r.
+func getMappingType(r *registry, sourceValue, targetValue reflect.Value) processingResultType {
 	switch {
+	// S -> I, with registered implementations
+	case isImplementationMapping(r, sourceValue.Type(), targetValue.Type()):
+		return interfaceMapping
 	// S -> S
 	case targetValue.Type().AssignableTo(sourceValue.Type()):
 		return directMapping
@@ -70,7 +175,7 @@ func getMappingType(sourceValue, targetValue reflect.Value) processingResultType
 		return structsMapping
 	// [] -> []
 	case targetValue.Kind() == reflect.Slice && sourceValue.Kind() == reflect.Slice:
-		return getSlicesMappingType(sourceValue, targetValue)
+		return getSlicesMappingType(r, sourceValue, targetValue)
 	// array -> array
 	case targetValue.Kind() == reflect.Array && sourceValue.Kind() == reflect.Array:
 		return getArraysMappingType(sourceValue, targetValue)
@@ -111,7 +216,12 @@ func getMapsMappingType(sourceValue, targetValue reflect.Value) processingResult
 }
 
 // getSlicesMappingType returns the processing type for the given slices.
-func getSlicesMappingType(sourceValue, targetValue reflect.Value) processingResultType {
+func getSlicesMappingType(r *registry, sourceValue, targetValue reflect.Value) processingResultType {
+	// [S] -> [I], with registered implementations
+	if isImplementationMapping(r, sourceValue.Type().Elem(), targetValue.Type().Elem()) {
+		return slicesMapping
+	}
+
 	var validCount int8
 	if sourceValue.Type().Elem().Kind() == reflect.Struct ||
 		(sourceValue.Type().Elem().Kind() == reflect.Ptr && sourceValue.Type().Elem().Elem().Kind() == reflect.Struct) {
@@ -136,7 +136,7 @@ func Test_getSlicesMappingType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getSlicesMappingType(tt.args.sourceValue, tt.args.targetValue); got != tt.want {
+			if got := getSlicesMappingType(loadRegistry(), tt.args.sourceValue, tt.args.targetValue); got != tt.want {
 				t.Errorf("getSlicesMappingType() = %v, want %v", got, tt.want)
 			}
 		})
@@ -320,7 +320,7 @@ func Test_getMappingType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getMappingType(tt.args.sourceValue, tt.args.targetValue); got != tt.want {
+			if got := getMappingType(loadRegistry(), tt.args.sourceValue, tt.args.targetValue); got != tt.want {
 				t.Errorf("getMappingType() = %v, want %v", got, tt.want)
 			}
 		})
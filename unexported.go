@@ -0,0 +1,44 @@
+package structsconv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnexported is returned when a rule reads or writes an unexported field and the Mapper does not allow it,
+// see AllowUnexported.
+var ErrUnexported = errors.New("unexported field")
+
+// AllowUnexported sets whether the Mapper reads and writes unexported fields, it does by default.
+//
+// Unexported fields are accessed through the unsafe package. When they are not allowed, the unexported fields
+// without rules are skipped, and the rules that read or write them fail with an error wrapping ErrUnexported.
+// Builds with the structsconv_safe tag do not use the unsafe package and never allow unexported fields.
+func AllowUnexported(allow bool) Option {
+	return func(m *Mapper) {
+		m.allowUnexported = allow
+	}
+}
+
+// unexportedAllowed reports whether the mapping reads and writes unexported fields.
+func (s *mappingState) unexportedAllowed() bool {
+	return unsafeAvailable && s.mapper.allowUnexported
+}
+
+// skipUnexportedField skips an unexported target field, returning an error if a rule maps it.
+func skipUnexportedField(key rulesKey, rules RulesSet, targetFieldName string, state *mappingState) error {
+	if rule := rules[targetFieldName]; rule != nil {
+		return fmt.Errorf("%w: target field '%s' of %s", ErrUnexported, targetFieldName, key.target)
+	}
+	logSkippedUnexported(state, key, targetFieldName)
+	return nil
+}
+
+// checkUnexportedSource returns an error if the source field is not readable by the mapping.
+func checkUnexportedSource(sourceValue reflect.Value, sourceFieldName string, state *mappingState) error {
+	if sourceValue.IsValid() && !sourceValue.CanInterface() && !state.unexportedAllowed() {
+		return fmt.Errorf("%w: source field '%s'", ErrUnexported, sourceFieldName)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build structsconv_safe
+
+package structsconv
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_AllowUnexported_safe(t *testing.T) {
+	src := accountDto{ID: "a1", Owner: "john", balance: 10}
+
+	t.Run("skipped by default", func(t *testing.T) {
+		registerAccountRules(t, RulesSet{"Holder": "Owner"})
+		got := account{balance: 5}
+		if err := New(WithLogger(nil)).MapE(&src, &got); err != nil {
+			t.Fatalf("MapE() error = %v", err)
+		}
+		if want := (account{ID: "a1", Holder: "john", balance: 5}); got != want {
+			t.Errorf("MapE() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("allowing has no effect", func(t *testing.T) {
+		registerAccountRules(t, RulesSet{"Holder": "Owner"})
+		var got account
+		if err := New(WithLogger(nil), AllowUnexported(true)).MapE(&src, &got); err != nil {
+			t.Fatalf("MapE() error = %v", err)
+		}
+		if want := (account{ID: "a1", Holder: "john"}); got != want {
+			t.Errorf("MapE() = %+v, want %+v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		rules RulesSet
+		path  string
+	}{
+		{name: "rule of unexported target", rules: RulesSet{"secret": func() string { return "s" }}, path: "secret"},
+		{name: "rule reading unexported source", rules: RulesSet{"Holder": "note"}, path: "Holder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerAccountRules(t, tt.rules)
+			var got account
+			err := New(WithLogger(nil)).MapE(&src, &got)
+			var me *MappingError
+			if !errors.Is(err, ErrUnexported) || !errors.As(err, &me) || me.Path != tt.path {
+				t.Errorf("MapE() error = %v, want ErrUnexported at %s", err, tt.path)
+			}
+		})
+	}
+}
+
+func Test_Clone_safe(t *testing.T) {
+	type source struct {
+		Names  []string
+		secret map[string]int
+	}
+
+	src := source{Names: []string{"a"}, secret: map[string]int{"s": 1}}
+	got := Clone(src)
+	got.Names[0] = "changed"
+	if src.Names[0] != "a" || got.secret != nil {
+		t.Errorf("Clone() = %+v, want a copy of Names and no secret", got)
+	}
+}
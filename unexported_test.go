@@ -0,0 +1,59 @@
+package structsconv
+
+import (
+	"errors"
+	"testing"
+)
+
+type accountDto struct {
+	ID      string
+	Owner   string
+	balance int
+	note    string
+}
+
+type account struct {
+	ID      string
+	Holder  string
+	balance int
+	secret  string
+}
+
+func registerAccountRules(t *testing.T, rules RulesSet) {
+	withRegistry(t, RulesDefinition{Source: accountDto{}, Target: account{}, Rules: rules})
+}
+
+func Test_AllowUnexported(t *testing.T) {
+	src := accountDto{ID: "a1", Owner: "john", balance: 10}
+
+	t.Run("skipped", func(t *testing.T) {
+		registerAccountRules(t, RulesSet{"Holder": "Owner"})
+		got := account{balance: 5}
+		if err := New(WithLogger(nil), AllowUnexported(false)).MapE(&src, &got); err != nil {
+			t.Fatalf("MapE() error = %v", err)
+		}
+		if want := (account{ID: "a1", Holder: "john", balance: 5}); got != want {
+			t.Errorf("MapE() = %+v, want %+v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		rules RulesSet
+		path  string
+	}{
+		{name: "rule of unexported target", rules: RulesSet{"secret": func() string { return "s" }}, path: "secret"},
+		{name: "rule reading unexported source", rules: RulesSet{"Holder": "note"}, path: "Holder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerAccountRules(t, tt.rules)
+			var got account
+			err := New(WithLogger(nil), AllowUnexported(false)).MapE(&src, &got)
+			var me *MappingError
+			if !errors.Is(err, ErrUnexported) || !errors.As(err, &me) || me.Path != tt.path {
+				t.Errorf("MapE() error = %v, want ErrUnexported at %s", err, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !structsconv_safe
+
+package structsconv
+
+import "testing"
+
+func Test_AllowUnexported_default(t *testing.T) {
+	registerAccountRules(t, RulesSet{"Holder": "Owner"})
+	src := accountDto{ID: "a1", Owner: "john", balance: 10}
+	var got account
+	if err := New(WithLogger(nil)).MapE(&src, &got); err != nil {
+		t.Fatalf("MapE() error = %v", err)
+	}
+	if want := (account{ID: "a1", Holder: "john", balance: 10}); got != want {
+		t.Errorf("MapE() = %+v, want %+v", got, want)
+	}
+}
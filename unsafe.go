@@ -0,0 +1,21 @@
+//go:build !structsconv_safe
+
+package structsconv
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// unsafeAvailable reports whether unexported fields can be accessed, see AllowUnexported.
+const unsafeAvailable = true
+
+func getUnexportedField(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+func setUnexportedField(field, value reflect.Value) {
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).
+		Elem().
+		Set(value)
+}
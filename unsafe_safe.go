@@ -0,0 +1,21 @@
+//go:build structsconv_safe
+
+package structsconv
+
+import (
+	"log"
+	"reflect"
+)
+
+// unsafeAvailable reports whether unexported fields can be accessed, see AllowUnexported.
+const unsafeAvailable = false
+
+// getUnexportedField returns the field as is, its value can be read but not interfaced or set.
+func getUnexportedField(field reflect.Value) reflect.Value {
+	return field
+}
+
+// setUnexportedField is never called, since unexported target fields are skipped.
+func setUnexportedField(field, _ reflect.Value) {
+	log.Panicf("ERROR: unexported field of type %s cannot be set in structsconv_safe builds", field.Type())
+}